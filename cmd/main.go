@@ -7,10 +7,13 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	serviceLambda "github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/awslabs/aws-lambda-go-api-proxy/gorillamux"
 	"github.com/twizar/common/pkg/client"
+	"github.com/twizar/tourneys/internal/adapters"
 	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/repository"
 	"github.com/twizar/tourneys/internal/ports"
 	"github.com/twizar/tourneys/internal/ports/converter"
 )
@@ -20,16 +23,26 @@ const (
 	envVarLambdaEndpoint                     = "TEAMS_LAMBDA_ENDPOINT"
 	envVarLambdaRegion                       = "TEAMS_LAMBDA_REGION"
 	envVarHTTPHeaderAccessControlAllowOrigin = "HTTP_HEADER_ACCESS_CONTROL_ALLOW_ORIGIN"
+	// envVarDynamoDBTable stores the tourneys and the fair draws in the DynamoDB table, envVarBoltPath stores them
+	// in the BoltDB file when the table isn't set. envVarMemoryStore keeps them in the memory of the instance
+	// when neither is set, without any of the three the function doesn't start.
+	envVarDynamoDBTable    = "TOURNEYS_DYNAMODB_TABLE"
+	envVarDynamoDBEndpoint = "TOURNEYS_DYNAMODB_ENDPOINT"
+	envVarDynamoDBRegion   = "TOURNEYS_DYNAMODB_REGION"
+	envVarBoltPath         = "TOURNEYS_BOLT_PATH"
+	envVarMemoryStore      = "TOURNEYS_MEMORY_STORE"
 )
 
 func main() {
 	lambdaName, lambdaEndpoint, lambdaRegion, accessControlAllowOrigin := requiredParams()
 
 	lambdaTeamsClient := configureTeamsClient(lambdaName, lambdaEndpoint, lambdaRegion)
-	tourneyGenerator := service.NewTourneyGenerator(lambdaTeamsClient)
+	tourneys, draws := configureRepositories()
+	tourneyGenerator := service.NewTourneyGenerator(lambdaTeamsClient, service.TimeSeedSource{}, tourneys)
 	dtoConverter := converter.NewConverter(lambdaTeamsClient)
+	fairDraw := service.NewFairDraw(draws)
 
-	server := ports.NewHTTPServer(tourneyGenerator, dtoConverter)
+	server := ports.NewHTTPServer(tourneyGenerator, service.NewTourneyCatalog(lambdaTeamsClient, tourneys), dtoConverter, fairDraw)
 	r := ports.ConfigureRouter(server)
 	adapter := gorillamux.New(r)
 	handler := ports.NewLambdaHandler(adapter, accessControlAllowOrigin)
@@ -66,3 +79,41 @@ func configureTeamsClient(lambdaName, lambdaEndpoint, lambdaRegion string) *clie
 
 	return client.NewAWSLambdaTeams(lambdaClient, lambdaName)
 }
+
+func configureRepositories() (repository.TourneyRepository, repository.DrawRepository) {
+	if table, varExists := os.LookupEnv(envVarDynamoDBTable); varExists {
+		conf := aws.NewConfig()
+
+		if region, varExists := os.LookupEnv(envVarDynamoDBRegion); varExists {
+			conf.Region = aws.String(region)
+		}
+
+		if endpoint, varExists := os.LookupEnv(envVarDynamoDBEndpoint); varExists {
+			conf.Endpoint = aws.String(endpoint)
+		}
+
+		client := dynamodb.New(session.Must(session.NewSession()), conf)
+
+		return adapters.NewDynamoDBTourneyRepository(client, table), adapters.NewDynamoDBDrawRepository(client, table)
+	}
+
+	boltPath, varExists := os.LookupEnv(envVarBoltPath)
+	if !varExists {
+		if _, varExists = os.LookupEnv(envVarMemoryStore); !varExists {
+			log.Panicf("none of the env vars `%s`, `%s` and `%s` exists, the tourneys have no storage",
+				envVarDynamoDBTable, envVarBoltPath, envVarMemoryStore)
+		}
+
+		log.Printf("WARNING: the tourneys and the fair draws are kept in memory, they're lost with the instance "+
+			"and other instances don't see them, set `%s` or `%s` to store them\n", envVarDynamoDBTable, envVarBoltPath)
+
+		return adapters.NewMemoryTourneyRepository(), adapters.NewMemoryDrawRepository()
+	}
+
+	tourneys, err := adapters.NewBoltTourneyRepository(boltPath)
+	if err != nil {
+		log.Panicf("configuring tourney repository error: %v", err)
+	}
+
+	return tourneys, adapters.NewBoltDrawRepository(tourneys)
+}
@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltDrawRepository keeps the draws as JSON records in the BoltDB file of the tourneys,
+// a file is opened by one process at a time.
+type BoltDrawRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltDrawRepository(tourneys *BoltTourneyRepository) *BoltDrawRepository {
+	return &BoltDrawRepository{db: tourneys.db}
+}
+
+func (r BoltDrawRepository) Save(draw *entity.DrawCommitment) error {
+	record := newDrawRecord(draw)
+	record.Version++
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding draw `%s` error: %w", draw.ID(), err)
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		version := 0
+
+		stored, err := loadDraw(tx, draw.ID())
+
+		switch {
+		case err == nil:
+			version = stored.Version()
+		case !errors.Is(err, repository.ErrDrawNotFound):
+			return err
+		}
+
+		if version != draw.Version() {
+			return repository.ErrVersionConflict
+		}
+
+		return tx.Bucket(drawsBucket).Put([]byte(draw.ID()), data)
+	})
+	if err != nil {
+		return fmt.Errorf("storing draw `%s` error: %w", draw.ID(), err)
+	}
+
+	draw.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r BoltDrawRepository) Get(id string) (*entity.DrawCommitment, error) {
+	var draw *entity.DrawCommitment
+
+	err := r.db.View(func(tx *bolt.Tx) (err error) {
+		draw, err = loadDraw(tx, id)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading draw `%s` error: %w", id, err)
+	}
+
+	return draw, nil
+}
+
+func loadDraw(tx *bolt.Tx, id string) (*entity.DrawCommitment, error) {
+	data := tx.Bucket(drawsBucket).Get([]byte(id))
+	if data == nil {
+		return nil, fmt.Errorf("draw `%s` error: %w", id, repository.ErrDrawNotFound)
+	}
+
+	var record drawRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("decoding draw `%s` error: %w", id, err)
+	}
+
+	return record.toEntity(), nil
+}
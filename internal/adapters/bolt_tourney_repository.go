@@ -0,0 +1,212 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+	bolt "go.etcd.io/bbolt"
+)
+
+const boltOpenTimeout = time.Second
+
+var (
+	tourneysBucket = []byte("tourneys")
+	// userIndexBucket keeps a bucket per user with the IDs of the user's tourneys by their index keys.
+	userIndexBucket = []byte("tourneys_by_user")
+	drawsBucket     = []byte("draws")
+)
+
+// BoltTourneyRepository keeps the tourneys as JSON records in an embedded BoltDB file.
+type BoltTourneyRepository struct {
+	db *bolt.DB
+}
+
+func NewBoltTourneyRepository(path string) (*BoltTourneyRepository, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening bolt db `%s` error: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{tourneysBucket, userIndexBucket, drawsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating buckets error: %w", err)
+	}
+
+	return &BoltTourneyRepository{db: db}, nil
+}
+
+func (r BoltTourneyRepository) Close() error {
+	if err := r.db.Close(); err != nil {
+		return fmt.Errorf("closing bolt db error: %w", err)
+	}
+
+	return nil
+}
+
+func (r BoltTourneyRepository) Save(tourney *entity.Tourney) error {
+	record := newTourneyRecord(tourney)
+	record.Version++
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding tourney `%s` error: %w", tourney.ID(), err)
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		version := 0
+
+		stored, err := loadTourney(tx, tourney.ID())
+
+		switch {
+		case err == nil:
+			version = stored.Version()
+		case !errors.Is(err, repository.ErrTourneyNotFound):
+			return err
+		}
+
+		if version != tourney.Version() {
+			return repository.ErrVersionConflict
+		}
+
+		if err = tx.Bucket(tourneysBucket).Put([]byte(tourney.ID()), data); err != nil {
+			return err
+		}
+
+		key := []byte(userIndexKey(tourney.CreatedAt(), tourney.ID()))
+
+		for _, userID := range tourney.UserIDs() {
+			userBucket, err := tx.Bucket(userIndexBucket).CreateBucketIfNotExists([]byte(userID))
+			if err != nil {
+				return err
+			}
+
+			if err = userBucket.Put(key, []byte(tourney.ID())); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("storing tourney `%s` error: %w", tourney.ID(), err)
+	}
+
+	tourney.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r BoltTourneyRepository) Get(id string) (*entity.Tourney, error) {
+	var tourney *entity.Tourney
+
+	err := r.db.View(func(tx *bolt.Tx) (err error) {
+		tourney, err = loadTourney(tx, id)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading tourney `%s` error: %w", id, err)
+	}
+
+	return tourney, nil
+}
+
+func (r BoltTourneyRepository) ListByUser(userID, cursor string, limit int) ([]*entity.Tourney, string, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		tourneys   []*entity.Tourney
+		nextCursor string
+	)
+
+	err = r.db.View(func(tx *bolt.Tx) error {
+		userBucket := tx.Bucket(userIndexBucket).Bucket([]byte(userID))
+		if userBucket == nil {
+			return nil
+		}
+
+		indexCursor := userBucket.Cursor()
+
+		key, id := indexCursor.Seek([]byte(after))
+		if key != nil && string(key) == after {
+			key, id = indexCursor.Next()
+		}
+
+		for ; key != nil; key, id = indexCursor.Next() {
+			if len(tourneys) == limit {
+				nextCursor = encodeCursor(userIndexKey(tourneys[limit-1].CreatedAt(), tourneys[limit-1].ID()))
+
+				break
+			}
+
+			tourney, err := loadTourney(tx, string(id))
+			if err != nil {
+				return err
+			}
+
+			tourneys = append(tourneys, tourney)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("listing tourneys of user `%s` error: %w", userID, err)
+	}
+
+	return tourneys, nextCursor, nil
+}
+
+func (r BoltTourneyRepository) Delete(id string) error {
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		tourney, err := loadTourney(tx, id)
+		if err != nil {
+			return err
+		}
+
+		key := []byte(userIndexKey(tourney.CreatedAt(), id))
+
+		for _, userID := range tourney.UserIDs() {
+			if userBucket := tx.Bucket(userIndexBucket).Bucket([]byte(userID)); userBucket != nil {
+				if err = userBucket.Delete(key); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Bucket(tourneysBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		return fmt.Errorf("deleting tourney `%s` error: %w", id, err)
+	}
+
+	return nil
+}
+
+func loadTourney(tx *bolt.Tx, id string) (*entity.Tourney, error) {
+	data := tx.Bucket(tourneysBucket).Get([]byte(id))
+	if data == nil {
+		return nil, fmt.Errorf("tourney `%s` error: %w", id, repository.ErrTourneyNotFound)
+	}
+
+	var record tourneyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("decoding tourney `%s` error: %w", id, err)
+	}
+
+	return record.toEntity(), nil
+}
@@ -0,0 +1,43 @@
+package adapters
+
+import "github.com/twizar/tourneys/internal/domain/entity"
+
+// drawRecord is the stored form of a fair draw.
+type drawRecord struct {
+	ID          string             `json:"id"`
+	ServerSeed  string             `json:"server_seed"`
+	Commitment  string             `json:"commitment"`
+	ClientSeeds []clientSeedRecord `json:"client_seeds,omitempty"`
+	Revealed    bool               `json:"revealed"`
+	Version     int                `json:"version"`
+}
+
+type clientSeedRecord struct {
+	UserID string `json:"user_id"`
+	Seed   string `json:"seed"`
+}
+
+func newDrawRecord(draw *entity.DrawCommitment) drawRecord {
+	record := drawRecord{
+		ID:         draw.ID(),
+		ServerSeed: draw.ServerSeed(),
+		Commitment: draw.Commitment(),
+		Revealed:   draw.Revealed(),
+		Version:    draw.Version(),
+	}
+
+	for _, clientSeed := range draw.ClientSeeds() {
+		record.ClientSeeds = append(record.ClientSeeds, clientSeedRecord{UserID: clientSeed.UserID(), Seed: clientSeed.Seed()})
+	}
+
+	return record
+}
+
+func (r drawRecord) toEntity() *entity.DrawCommitment {
+	var clientSeeds []*entity.ClientSeed
+	for _, clientSeed := range r.ClientSeeds {
+		clientSeeds = append(clientSeeds, entity.NewClientSeed(clientSeed.UserID, clientSeed.Seed))
+	}
+
+	return entity.RestoreDrawCommitment(r.ID, r.ServerSeed, r.Commitment, clientSeeds, r.Revealed, r.Version)
+}
@@ -0,0 +1,64 @@
+package adapters_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+func TestDrawRepositories(t *testing.T) {
+	t.Parallel()
+
+	boltRepository, err := adapters.NewBoltTourneyRepository(filepath.Join(t.TempDir(), "tourneys.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, boltRepository.Close())
+	})
+
+	for name, draws := range map[string]repository.DrawRepository{
+		"memory":        adapters.NewMemoryDrawRepository(),
+		"bolt":          adapters.NewBoltDrawRepository(boltRepository),
+		"dynamodb fake": adapters.NewDynamoDBDrawRepository(newFakeDynamoDB(0), fakeDynamoDBTable),
+	} {
+		testDrawRepository(t, name, draws)
+	}
+}
+
+func testDrawRepository(t *testing.T, name string, draws repository.DrawRepository) {
+	t.Helper()
+
+	draw := entity.NewDrawCommitment("d1", "server-seed", "commitment")
+	require.NoError(t, draws.Save(draw), name)
+	assert.Equal(t, 1, draw.Version(), name)
+
+	// another request has loaded the draw before the first one stored its change.
+	first, err := draws.Get(draw.ID())
+	require.NoError(t, err, name)
+	assert.Equal(t, draw, first, name)
+
+	second, err := draws.Get(draw.ID())
+	require.NoError(t, err, name)
+
+	require.NoError(t, first.AddClientSeed(entity.NewClientSeed("user-1", "lucky")), name)
+	require.NoError(t, draws.Save(first), name)
+	assert.Equal(t, 2, first.Version(), name)
+
+	require.NoError(t, second.Reveal(), name)
+	assert.ErrorIs(t, draws.Save(second), repository.ErrVersionConflict, name)
+
+	stored, err := draws.Get(draw.ID())
+	require.NoError(t, err, name)
+	assert.Equal(t, first, stored, name)
+	assert.False(t, stored.Revealed(), name)
+
+	// a new draw can't take the ID of a stored one.
+	assert.ErrorIs(t, draws.Save(entity.NewDrawCommitment("d1", "other", "other")), repository.ErrVersionConflict, name)
+
+	_, err = draws.Get("unknown")
+	assert.ErrorIs(t, err, repository.ErrDrawNotFound, name)
+}
@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+// A draw is an item of the tourneys table under its own partition.
+const (
+	dynamoDBDrawPrefix  = "DRAW#"
+	dynamoDBDrawSortKey = "DRAW"
+)
+
+// DynamoDBDrawRepository keeps the draws as JSON records in the DynamoDB table of the tourneys.
+type DynamoDBDrawRepository struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+func NewDynamoDBDrawRepository(client dynamodbiface.DynamoDBAPI, table string) *DynamoDBDrawRepository {
+	return &DynamoDBDrawRepository{client: client, table: table}
+}
+
+func (r DynamoDBDrawRepository) Save(draw *entity.DrawCommitment) error {
+	record := newDrawRecord(draw)
+	record.Version++
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding draw `%s` error: %w", draw.ID(), err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.table),
+		Item: map[string]*dynamodb.AttributeValue{
+			dynamoDBPartitionKey: {S: aws.String(dynamoDBDrawPrefix + draw.ID())},
+			dynamoDBSortKey:      {S: aws.String(dynamoDBDrawSortKey)},
+			dynamoDBDataKey:      {S: aws.String(string(data))},
+			dynamoDBVersionKey:   {N: aws.String(strconv.Itoa(record.Version))},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#version)"),
+		ExpressionAttributeNames: map[string]*string{"#version": aws.String(dynamoDBVersionKey)},
+	}
+
+	if draw.Version() > 0 {
+		input.ConditionExpression = aws.String("#version = :version")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":version": {N: aws.String(strconv.Itoa(draw.Version()))},
+		}
+	}
+
+	if _, err = r.client.PutItem(input); err != nil {
+		if isConditionFailed(err) {
+			err = repository.ErrVersionConflict
+		}
+
+		return fmt.Errorf("storing draw `%s` error: %w", draw.ID(), err)
+	}
+
+	draw.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r DynamoDBDrawRepository) Get(id string) (*entity.DrawCommitment, error) {
+	output, err := r.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			dynamoDBPartitionKey: {S: aws.String(dynamoDBDrawPrefix + id)},
+			dynamoDBSortKey:      {S: aws.String(dynamoDBDrawSortKey)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading draw `%s` error: %w", id, err)
+	}
+
+	if output.Item == nil || output.Item[dynamoDBDataKey] == nil {
+		return nil, fmt.Errorf("draw `%s` error: %w", id, repository.ErrDrawNotFound)
+	}
+
+	var record drawRecord
+	if err = json.Unmarshal([]byte(aws.StringValue(output.Item[dynamoDBDataKey].S)), &record); err != nil {
+		return nil, fmt.Errorf("decoding draw `%s` error: %w", id, err)
+	}
+
+	return record.toEntity(), nil
+}
+
+// isConditionFailed tells a write was refused by its condition, alone or as a part of a transaction.
+func isConditionFailed(err error) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for _, reason := range canceled.CancellationReasons {
+			if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var awsErr awserr.Error
+
+	return errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
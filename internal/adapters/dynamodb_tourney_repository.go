@@ -0,0 +1,425 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+// The single table keeps a tourney item and an item per user of the tourney under the tourney partition,
+// the user items are indexed by the user to page the user's tourneys in the order they were created.
+// The version attribute of the tourney item makes the writes conditional, its data is a gzipped JSON record
+// as an item takes at most 400 KB and the results of a large tourney take more as plain JSON.
+const (
+	dynamoDBPartitionKey = "pk"
+	dynamoDBSortKey      = "sk"
+	dynamoDBUserKey      = "user_pk"
+	dynamoDBUserSortKey  = "user_sk"
+	dynamoDBDataKey      = "data"
+	dynamoDBVersionKey   = "version"
+	dynamoDBUserIndex    = "user_index"
+
+	dynamoDBTourneyPrefix  = "TOURNEY#"
+	dynamoDBUserPrefix     = "USER#"
+	dynamoDBTourneySortKey = "TOURNEY"
+
+	// a batch get takes at most 100 keys, the keys it leaves unprocessed are asked again after a growing delay.
+	dynamoDBBatchGetKeys     = 100
+	dynamoDBBatchGetAttempts = 5
+	dynamoDBBatchGetDelay    = 50 * time.Millisecond
+
+	// a transaction takes at most 100 items.
+	dynamoDBTransactionItems = 100
+	dynamoDBItemSizeLimit    = 400 * 1024
+)
+
+var errUnprocessedKeys = errors.New("unprocessed keys error")
+
+// DynamoDBTourneyRepository keeps the tourneys as JSON records in a DynamoDB table.
+type DynamoDBTourneyRepository struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+func NewDynamoDBTourneyRepository(client dynamodbiface.DynamoDBAPI, table string) *DynamoDBTourneyRepository {
+	return &DynamoDBTourneyRepository{client: client, table: table}
+}
+
+// CreateTable creates the table with its user index and waits until it's ready, it's meant for local setups
+// as deployed tables are managed with the infrastructure.
+func (r DynamoDBTourneyRepository) CreateTable() error {
+	stringAttribute := func(name string) *dynamodb.AttributeDefinition {
+		return &dynamodb.AttributeDefinition{AttributeName: aws.String(name), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)}
+	}
+
+	keySchema := func(hashKey, rangeKey string) []*dynamodb.KeySchemaElement {
+		return []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String(hashKey), KeyType: aws.String(dynamodb.KeyTypeHash)},
+			{AttributeName: aws.String(rangeKey), KeyType: aws.String(dynamodb.KeyTypeRange)},
+		}
+	}
+
+	_, err := r.client.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(r.table),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			stringAttribute(dynamoDBPartitionKey),
+			stringAttribute(dynamoDBSortKey),
+			stringAttribute(dynamoDBUserKey),
+			stringAttribute(dynamoDBUserSortKey),
+		},
+		KeySchema:   keySchema(dynamoDBPartitionKey, dynamoDBSortKey),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+			IndexName:  aws.String(dynamoDBUserIndex),
+			KeySchema:  keySchema(dynamoDBUserKey, dynamoDBUserSortKey),
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("creating table `%s` error: %w", r.table, err)
+	}
+
+	if err = r.client.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: aws.String(r.table)}); err != nil {
+		return fmt.Errorf("waiting for table `%s` error: %w", r.table, err)
+	}
+
+	return nil
+}
+
+func (r DynamoDBTourneyRepository) Save(tourney *entity.Tourney) error {
+	record := newTourneyRecord(tourney)
+	record.Version++
+
+	data, err := encodeTourneyData(record)
+	if err != nil {
+		return fmt.Errorf("encoding tourney `%s` error: %w", tourney.ID(), err)
+	}
+
+	tourneyPut := &dynamodb.Put{
+		TableName: aws.String(r.table),
+		Item: map[string]*dynamodb.AttributeValue{
+			dynamoDBPartitionKey: {S: aws.String(dynamoDBTourneyPrefix + tourney.ID())},
+			dynamoDBSortKey:      {S: aws.String(dynamoDBTourneySortKey)},
+			dynamoDBDataKey:      {B: data},
+			dynamoDBVersionKey:   {N: aws.String(strconv.Itoa(record.Version))},
+		},
+	}
+	versionCondition(tourneyPut, tourney.Version())
+
+	if size := itemSize(tourneyPut.Item); size > dynamoDBItemSizeLimit {
+		return fmt.Errorf("storing tourney `%s` of %d bytes error: %w", tourney.ID(), size, repository.ErrTourneyTooLarge)
+	}
+
+	// the user items go before the tourney item, so a save failing on the way leaves user items at most,
+	// the listing skips them while there is no tourney item.
+	items := make([]*dynamodb.TransactWriteItem, 0, len(tourney.UserIDs())+1)
+
+	for _, userID := range tourney.UserIDs() {
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName: aws.String(r.table),
+				Item: map[string]*dynamodb.AttributeValue{
+					dynamoDBPartitionKey: {S: aws.String(dynamoDBTourneyPrefix + tourney.ID())},
+					dynamoDBSortKey:      {S: aws.String(dynamoDBUserPrefix + userID)},
+					dynamoDBUserKey:      {S: aws.String(dynamoDBUserPrefix + userID)},
+					dynamoDBUserSortKey:  {S: aws.String(userIndexKey(tourney.CreatedAt(), tourney.ID()))},
+				},
+			},
+		})
+	}
+
+	items = append(items, &dynamodb.TransactWriteItem{Put: tourneyPut})
+
+	if err = r.transactWrite(items); err != nil {
+		if isConditionFailed(err) {
+			err = repository.ErrVersionConflict
+		}
+
+		return fmt.Errorf("storing tourney `%s` error: %w", tourney.ID(), err)
+	}
+
+	tourney.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r DynamoDBTourneyRepository) Get(id string) (*entity.Tourney, error) {
+	output, err := r.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key:       tourneyItemKey(id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading tourney `%s` error: %w", id, err)
+	}
+
+	if output.Item == nil || output.Item[dynamoDBDataKey] == nil {
+		return nil, fmt.Errorf("tourney `%s` error: %w", id, repository.ErrTourneyNotFound)
+	}
+
+	return decodeTourneyItem(id, output.Item)
+}
+
+func (r DynamoDBTourneyRepository) ListByUser(userID, cursor string, limit int) ([]*entity.Tourney, string, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String(dynamoDBUserIndex),
+		KeyConditionExpression: aws.String("#user = :user"),
+		ExpressionAttributeNames: map[string]*string{
+			"#user": aws.String(dynamoDBUserKey),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user": {S: aws.String(dynamoDBUserPrefix + userID)},
+		},
+		// one more key tells whether there is a next page.
+		Limit: aws.Int64(int64(limit + 1)),
+	}
+
+	if after != "" {
+		input.KeyConditionExpression = aws.String("#user = :user AND #created > :after")
+		input.ExpressionAttributeNames["#created"] = aws.String(dynamoDBUserSortKey)
+		input.ExpressionAttributeValues[":after"] = &dynamodb.AttributeValue{S: aws.String(after)}
+	}
+
+	var keys []string
+
+	for len(keys) <= limit {
+		output, err := r.client.Query(input)
+		if err != nil {
+			return nil, "", fmt.Errorf("querying tourneys of user `%s` error: %w", userID, err)
+		}
+
+		for _, item := range output.Items {
+			keys = append(keys, aws.StringValue(item[dynamoDBUserSortKey].S))
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	nextCursor := ""
+	if len(keys) > limit {
+		keys = keys[:limit]
+		nextCursor = encodeCursor(keys[limit-1])
+	}
+
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key[strings.Index(key, userIndexKeySeparator)+1:]
+	}
+
+	tourneys, err := r.batchGet(ids)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tourneys, nextCursor, nil
+}
+
+// batchGet loads the tourneys in the order of the IDs. The user index is eventually consistent,
+// so it may still list a deleted tourney, such a tourney is skipped.
+func (r DynamoDBTourneyRepository) batchGet(ids []string) ([]*entity.Tourney, error) {
+	tourneysByID := make(map[string]*entity.Tourney, len(ids))
+
+	for start := 0; start < len(ids); start += dynamoDBBatchGetKeys {
+		end := start + dynamoDBBatchGetKeys
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		keys := make([]map[string]*dynamodb.AttributeValue, end-start)
+		for i, id := range ids[start:end] {
+			keys[i] = tourneyItemKey(id)
+		}
+
+		requestItems := map[string]*dynamodb.KeysAndAttributes{r.table: {Keys: keys}}
+		delay := dynamoDBBatchGetDelay
+
+		for attempt := 1; len(requestItems) > 0; attempt++ {
+			if attempt > dynamoDBBatchGetAttempts {
+				return nil, fmt.Errorf("loading tourneys after %d attempts error: %w", dynamoDBBatchGetAttempts, errUnprocessedKeys)
+			}
+
+			if attempt > 1 {
+				time.Sleep(delay)
+				delay *= 2
+			}
+
+			output, err := r.client.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: requestItems})
+			if err != nil {
+				return nil, fmt.Errorf("loading tourneys error: %w", err)
+			}
+
+			for _, item := range output.Responses[r.table] {
+				id := strings.TrimPrefix(aws.StringValue(item[dynamoDBPartitionKey].S), dynamoDBTourneyPrefix)
+
+				if tourneysByID[id], err = decodeTourneyItem(id, item); err != nil {
+					return nil, err
+				}
+			}
+
+			requestItems = output.UnprocessedKeys
+		}
+	}
+
+	tourneys := make([]*entity.Tourney, 0, len(ids))
+
+	for _, id := range ids {
+		if tourney, ok := tourneysByID[id]; ok {
+			tourneys = append(tourneys, tourney)
+		}
+	}
+
+	return tourneys, nil
+}
+
+func (r DynamoDBTourneyRepository) Delete(id string) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		KeyConditionExpression: aws.String("#tourney = :tourney"),
+		ExpressionAttributeNames: map[string]*string{
+			"#tourney": aws.String(dynamoDBPartitionKey),
+			"#sort":    aws.String(dynamoDBSortKey),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":tourney": {S: aws.String(dynamoDBTourneyPrefix + id)},
+		},
+		ProjectionExpression: aws.String("#tourney, #sort"),
+	}
+
+	var items []*dynamodb.TransactWriteItem
+
+	for {
+		output, err := r.client.Query(input)
+		if err != nil {
+			return fmt.Errorf("querying tourney `%s` error: %w", id, err)
+		}
+
+		for _, key := range output.Items {
+			item := &dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{TableName: aws.String(r.table), Key: key}}
+
+			// the tourney item goes first, so a deletion failing on the way leaves user items at most.
+			if aws.StringValue(key[dynamoDBSortKey].S) == dynamoDBTourneySortKey {
+				items = append([]*dynamodb.TransactWriteItem{item}, items...)
+			} else {
+				items = append(items, item)
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	if len(items) == 0 {
+		return fmt.Errorf("tourney `%s` error: %w", id, repository.ErrTourneyNotFound)
+	}
+
+	if err := r.transactWrite(items); err != nil {
+		return fmt.Errorf("deleting tourney `%s` error: %w", id, err)
+	}
+
+	return nil
+}
+
+// transactWrite writes the items in transactions of at most 100 items, one after another in the order of the items.
+func (r DynamoDBTourneyRepository) transactWrite(items []*dynamodb.TransactWriteItem) error {
+	for start := 0; start < len(items); start += dynamoDBTransactionItems {
+		end := start + dynamoDBTransactionItems
+		if end > len(items) {
+			end = len(items)
+		}
+
+		if _, err := r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items[start:end]}); err != nil {
+			return fmt.Errorf("writing items %d to %d error: %w", start, end, err)
+		}
+	}
+
+	return nil
+}
+
+// versionCondition makes the put store over the version only, a new item's version attribute must not exist.
+func versionCondition(put *dynamodb.Put, version int) {
+	put.ExpressionAttributeNames = map[string]*string{"#version": aws.String(dynamoDBVersionKey)}
+	put.ConditionExpression = aws.String("attribute_not_exists(#version)")
+
+	if version > 0 {
+		put.ConditionExpression = aws.String("#version = :version")
+		put.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":version": {N: aws.String(strconv.Itoa(version))},
+		}
+	}
+}
+
+func tourneyItemKey(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		dynamoDBPartitionKey: {S: aws.String(dynamoDBTourneyPrefix + id)},
+		dynamoDBSortKey:      {S: aws.String(dynamoDBTourneySortKey)},
+	}
+}
+
+// itemSize counts the item the way DynamoDB does: the lengths of the attribute names and values.
+func itemSize(item map[string]*dynamodb.AttributeValue) (size int) {
+	for name, value := range item {
+		size += len(name) + len(aws.StringValue(value.S)) + len(aws.StringValue(value.N)) + len(value.B)
+	}
+
+	return
+}
+
+func encodeTourneyData(record tourneyRecord) ([]byte, error) {
+	var data bytes.Buffer
+
+	writer := gzip.NewWriter(&data)
+	if err := json.NewEncoder(writer).Encode(record); err != nil {
+		return nil, fmt.Errorf("encoding tourney record error: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("compressing tourney record error: %w", err)
+	}
+
+	return data.Bytes(), nil
+}
+
+// decodeTourneyItem reads the gzipped data, the items stored before the data was compressed keep it as a string.
+func decodeTourneyItem(id string, item map[string]*dynamodb.AttributeValue) (*entity.Tourney, error) {
+	var data io.Reader = strings.NewReader(aws.StringValue(item[dynamoDBDataKey].S))
+
+	if item[dynamoDBDataKey].B != nil {
+		reader, err := gzip.NewReader(bytes.NewReader(item[dynamoDBDataKey].B))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing tourney `%s` error: %w", id, err)
+		}
+
+		data = reader
+	}
+
+	var record tourneyRecord
+	if err := json.NewDecoder(data).Decode(&record); err != nil {
+		return nil, fmt.Errorf("decoding tourney `%s` error: %w", id, err)
+	}
+
+	return record.toEntity(), nil
+}
@@ -0,0 +1,449 @@
+package adapters_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+const fakeDynamoDBTable = "tourneys"
+
+func TestDynamoDBTourneyRepository_Fake(t *testing.T) {
+	t.Parallel()
+
+	testTourneyRepository(t, "dynamodb fake", adapters.NewDynamoDBTourneyRepository(newFakeDynamoDB(2), fakeDynamoDBTable))
+}
+
+func TestDynamoDBTourneyRepository_Save(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeDynamoDB(0)
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, fakeDynamoDBTable)
+
+	tourney := newTourney()
+	require.NoError(t, tourneys.Save(tourney))
+	require.Len(t, client.transactions, 1)
+
+	// an item per user is indexed by the user and the creation, the tourney item keeps the data and goes last.
+	items := client.transactions[0]
+	require.Len(t, items, 3)
+
+	for i, userID := range []string{"user-1", "user-2"} {
+		userItem := items[i].Put.Item
+		assert.Equal(t, fakeDynamoDBTable, aws.StringValue(items[i].Put.TableName))
+		assert.Equal(t, "TOURNEY#3f2a", aws.StringValue(userItem["pk"].S))
+		assert.Equal(t, "USER#"+userID, aws.StringValue(userItem["sk"].S))
+		assert.Equal(t, "USER#"+userID, aws.StringValue(userItem["user_pk"].S))
+		assert.Equal(t, "01641304050000000000/3f2a", aws.StringValue(userItem["user_sk"].S))
+		assert.NotContains(t, userItem, "data")
+	}
+
+	tourneyItem := items[2].Put.Item
+	assert.Equal(t, "TOURNEY#3f2a", aws.StringValue(tourneyItem["pk"].S))
+	assert.Equal(t, "TOURNEY", aws.StringValue(tourneyItem["sk"].S))
+	assert.NotEmpty(t, tourneyItem["data"].B)
+	assert.Equal(t, "1", aws.StringValue(tourneyItem["version"].N))
+	assert.Equal(t, "attribute_not_exists(#version)", aws.StringValue(items[2].Put.ConditionExpression))
+	assert.NotContains(t, tourneyItem, "user_pk")
+}
+
+func TestDynamoDBTourneyRepository_StringData(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeDynamoDB(0)
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, fakeDynamoDBTable)
+
+	// the tourneys stored before the data was compressed keep it as a string, without a version.
+	_, err := client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(fakeDynamoDBTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"pk":   {S: aws.String("TOURNEY#1b2c")},
+			"sk":   {S: aws.String("TOURNEY")},
+			"data": {S: aws.String(`{"id":"1b2c","groups_count":1,"teams_per_group":2,"seed":3,"groups":[]}`)},
+		},
+	})
+	require.NoError(t, err)
+
+	tourney, err := tourneys.Get("1b2c")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tourney.TeamsPerGroup())
+	assert.Zero(t, tourney.Version())
+
+	require.NoError(t, tourneys.Save(tourney))
+
+	stored, err := tourneys.Get("1b2c")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Version())
+}
+
+func TestDynamoDBTourneyRepository_ManyUsers(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeDynamoDB(0)
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, fakeDynamoDBTable)
+
+	slots := make([]*entity.GroupSlot, 250)
+	for i := range slots {
+		slots[i] = entity.NewGroupSlot(fmt.Sprintf("user-%03d", i), fmt.Sprintf("team-%03d", i))
+	}
+
+	group := entity.NewGroup("a", slots)
+	group.AssignMatchdays([]*entity.Matchday{})
+
+	tourney := entity.NewTourney("", 1, len(slots), 1, []*entity.Group{group})
+	tourney.AssignID("5e6f", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	// a transaction takes at most 100 items, the tourney item goes with the last ones.
+	require.NoError(t, tourneys.Save(tourney))
+	require.Len(t, client.transactions, 3)
+	assert.Len(t, client.transactions[0], 100)
+	assert.Len(t, client.transactions[1], 100)
+	require.Len(t, client.transactions[2], 51)
+	assert.Equal(t, "TOURNEY", aws.StringValue(client.transactions[2][50].Put.Item["sk"].S))
+
+	stored, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err)
+	assert.Equal(t, tourney, stored)
+
+	page, _, err := tourneys.ListByUser("user-249", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{tourney.ID()}, tourneyIDs(page))
+
+	// the tourney item goes with the first ones.
+	require.NoError(t, tourneys.Delete(tourney.ID()))
+	require.Len(t, client.transactions, 6)
+	assert.Len(t, client.transactions[3], 100)
+	assert.Equal(t, "TOURNEY", aws.StringValue(client.transactions[3][0].Delete.Key["sk"].S))
+	assert.Len(t, client.transactions[5], 51)
+	assert.Empty(t, client.items)
+}
+
+func TestDynamoDBTourneyRepository_ItemSize(t *testing.T) {
+	t.Parallel()
+
+	tourneys := adapters.NewDynamoDBTourneyRepository(newFakeDynamoDB(0), fakeDynamoDBTable)
+
+	// the results of every fixture of 16 groups of 32 teams take a few megabytes as plain JSON.
+	groups := make([]*entity.Group, 16)
+
+	for i := range groups {
+		slots := make([]*entity.GroupSlot, 32)
+		for j := range slots {
+			slots[j] = entity.NewGroupSlot("user-1", fmt.Sprintf("team-%02d-%02d", i, j))
+		}
+
+		groups[i] = entity.NewGroup(fmt.Sprintf("group-%02d", i), slots)
+		groups[i].AssignMatchdays([]*entity.Matchday{})
+
+		for home := range slots {
+			for away := range slots {
+				if home != away {
+					groups[i].AddMatch(entity.NewMatch(home, away, home%5, away%3))
+				}
+			}
+		}
+	}
+
+	tourney := entity.NewTourney("", len(groups), 32, 1, groups)
+	tourney.AssignID("7a8b", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, tourneys.Save(tourney))
+
+	stored, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err)
+	assert.Equal(t, tourney, stored)
+
+	// random team IDs don't compress, so the tourney item would exceed 400 KB.
+	slots := make([]*entity.GroupSlot, 512)
+
+	for i := range slots {
+		teamID := make([]byte, 1024)
+		_, err = rand.Read(teamID)
+		require.NoError(t, err)
+
+		slots[i] = entity.NewGroupSlot("user-1", hex.EncodeToString(teamID))
+	}
+
+	tourney = entity.NewTourney("", 1, len(slots), 1, []*entity.Group{entity.NewGroup("a", slots)})
+	tourney.AssignID("9c0d", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.ErrorIs(t, tourneys.Save(tourney), repository.ErrTourneyTooLarge)
+
+	_, err = tourneys.Get(tourney.ID())
+	assert.ErrorIs(t, err, repository.ErrTourneyNotFound)
+}
+
+func TestDynamoDBTourneyRepository_ListByUser(t *testing.T) {
+	t.Parallel()
+
+	// every query returns a single key, so a page is read across several LastEvaluatedKey.
+	client := newFakeDynamoDB(1)
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, fakeDynamoDBTable)
+
+	for _, id := range []string{"a1", "b2", "c3", "d4"} {
+		tourney := newTourney()
+		tourney.AssignID(id, tourney.CreatedAt())
+		require.NoError(t, tourneys.Save(tourney))
+	}
+
+	page, nextCursor, err := tourneys.ListByUser("user-1", "", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b2", "c3"}, tourneyIDs(page))
+	assert.NotEmpty(t, nextCursor)
+	assert.Equal(t, 4, client.queries)
+
+	// the index still lists a tourney which has been deleted, and a batch leaves a key for the next attempt.
+	client.deleteItem("TOURNEY#b2", "TOURNEY")
+	client.unprocessedBatches = 1
+
+	page, _, err = tourneys.ListByUser("user-1", "", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "c3"}, tourneyIDs(page))
+
+	page, nextCursor, err = tourneys.ListByUser("user-1", nextCursor, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d4"}, tourneyIDs(page))
+	assert.Empty(t, nextCursor)
+}
+
+func TestDynamoDBTourneyRepository_Delete(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeDynamoDB(0)
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, fakeDynamoDBTable)
+
+	tourney := newTourney()
+	require.NoError(t, tourneys.Save(tourney))
+	require.NoError(t, tourneys.Delete(tourney.ID()))
+
+	// the tourney item and the user items go at once, by their keys.
+	require.Len(t, client.transactions, 2)
+
+	var deletedKeys []string
+
+	for _, item := range client.transactions[1] {
+		require.NotNil(t, item.Delete)
+		assert.Len(t, item.Delete.Key, 2)
+		deletedKeys = append(deletedKeys, aws.StringValue(item.Delete.Key["sk"].S))
+	}
+
+	assert.ElementsMatch(t, []string{"TOURNEY", "USER#user-1", "USER#user-2"}, deletedKeys)
+	assert.Empty(t, client.items)
+
+	_, err := tourneys.Get(tourney.ID())
+	assert.ErrorIs(t, err, repository.ErrTourneyNotFound)
+	assert.ErrorIs(t, tourneys.Delete(tourney.ID()), repository.ErrTourneyNotFound)
+}
+
+// fakeDynamoDB keeps the items of a table in memory and answers the calls of the tourney repository,
+// a query returns at most pageSize items, zero page size leaves the query limit alone.
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mutex    sync.Mutex
+	items    map[[2]string]map[string]*dynamodb.AttributeValue
+	pageSize int
+
+	transactions [][]*dynamodb.TransactWriteItem
+	queries      int
+	// unprocessedBatches is the number of batch gets which leave their last key unprocessed.
+	unprocessedBatches int
+}
+
+func newFakeDynamoDB(pageSize int) *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[[2]string]map[string]*dynamodb.AttributeValue), pageSize: pageSize}
+}
+
+// TransactWriteItems checks the conditions of the puts first and cancels the whole transaction if one fails.
+func (db *fakeDynamoDB) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	db.transactions = append(db.transactions, input.TransactItems)
+
+	for _, item := range input.TransactItems {
+		if item.Put != nil && db.conditionFails(item.Put.Item, item.Put.ConditionExpression, item.Put.ExpressionAttributeValues) {
+			return nil, &dynamodb.TransactionCanceledException{
+				Message_:            aws.String("transaction canceled"),
+				CancellationReasons: []*dynamodb.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+			}
+		}
+	}
+
+	for _, item := range input.TransactItems {
+		switch {
+		case item.Put != nil:
+			db.items[itemKey(item.Put.Item)] = item.Put.Item
+		case item.Delete != nil:
+			delete(db.items, itemKey(item.Delete.Key))
+		}
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (db *fakeDynamoDB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if db.conditionFails(input.Item, input.ConditionExpression, input.ExpressionAttributeValues) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "version condition failed", nil)
+	}
+
+	db.items[itemKey(input.Item)] = input.Item
+
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// conditionFails checks the version conditions of the repositories against the stored item.
+func (db *fakeDynamoDB) conditionFails(
+	item map[string]*dynamodb.AttributeValue, condition *string, values map[string]*dynamodb.AttributeValue,
+) bool {
+	stored := db.items[itemKey(item)]
+
+	switch aws.StringValue(condition) {
+	case "attribute_not_exists(#version)":
+		return stored != nil && stored["version"] != nil
+	case "#version = :version":
+		return stored == nil || stored["version"] == nil ||
+			aws.StringValue(stored["version"].N) != aws.StringValue(values[":version"].N)
+	default:
+		return false
+	}
+}
+
+func (db *fakeDynamoDB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	return &dynamodb.GetItemOutput{Item: db.items[itemKey(input.Key)]}, nil
+}
+
+func (db *fakeDynamoDB) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	keys := input.RequestItems[fakeDynamoDBTable].Keys
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+
+	if db.unprocessedBatches > 0 && len(keys) > 1 {
+		db.unprocessedBatches--
+		output.UnprocessedKeys = map[string]*dynamodb.KeysAndAttributes{
+			fakeDynamoDBTable: {Keys: keys[len(keys)-1:]},
+		}
+		keys = keys[:len(keys)-1]
+	}
+
+	for _, key := range keys {
+		if item, ok := db.items[itemKey(key)]; ok {
+			output.Responses[fakeDynamoDBTable] = append(output.Responses[fakeDynamoDBTable], item)
+		}
+	}
+
+	return output, nil
+}
+
+// Query reads the tourney partition or, by the user index, the user partition after the :after key.
+func (db *fakeDynamoDB) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	db.queries++
+
+	partitionKey, sortKey, partition := "pk", "sk", input.ExpressionAttributeValues[":tourney"]
+	if input.IndexName != nil {
+		partitionKey, sortKey, partition = "user_pk", "user_sk", input.ExpressionAttributeValues[":user"]
+	}
+
+	after := ""
+	if input.ExpressionAttributeValues[":after"] != nil {
+		after = aws.StringValue(input.ExpressionAttributeValues[":after"].S)
+	}
+
+	if input.ExclusiveStartKey != nil {
+		after = aws.StringValue(input.ExclusiveStartKey[sortKey].S)
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+
+	for _, item := range db.items {
+		if item[partitionKey] != nil && aws.StringValue(item[partitionKey].S) == aws.StringValue(partition.S) &&
+			aws.StringValue(item[sortKey].S) > after {
+			items = append(items, project(item, input))
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return aws.StringValue(items[i][sortKey].S) < aws.StringValue(items[j][sortKey].S)
+	})
+
+	limit := len(items)
+	if input.Limit != nil && int(aws.Int64Value(input.Limit)) < limit {
+		limit = int(aws.Int64Value(input.Limit))
+	}
+
+	if db.pageSize > 0 && db.pageSize < limit {
+		limit = db.pageSize
+	}
+
+	output := &dynamodb.QueryOutput{Items: items[:limit]}
+	if limit < len(items) {
+		output.LastEvaluatedKey = items[limit-1]
+	}
+
+	return output, nil
+}
+
+func (db *fakeDynamoDB) deleteItem(partitionKey, sortKey string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	delete(db.items, [2]string{partitionKey, sortKey})
+}
+
+func itemKey(item map[string]*dynamodb.AttributeValue) [2]string {
+	return [2]string{aws.StringValue(item["pk"].S), aws.StringValue(item["sk"].S)}
+}
+
+// project leaves the attributes a query reads: the keys of the table and the index for the keys only index,
+// the keys of the table for the repository's projections.
+func project(item map[string]*dynamodb.AttributeValue, input *dynamodb.QueryInput) map[string]*dynamodb.AttributeValue {
+	names := []string{"pk", "sk"}
+
+	switch {
+	case input.IndexName != nil:
+		names = append(names, "user_pk", "user_sk")
+	case input.ProjectionExpression == nil:
+		return item
+	}
+
+	projected := make(map[string]*dynamodb.AttributeValue, len(names))
+	for _, name := range names {
+		projected[name] = item[name]
+	}
+
+	return projected
+}
+
+func tourneyIDs(tourneys []*entity.Tourney) []string {
+	ids := make([]string, len(tourneys))
+	for i, tourney := range tourneys {
+		ids[i] = tourney.ID()
+	}
+
+	return ids
+}
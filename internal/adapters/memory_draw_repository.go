@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+// MemoryDrawRepository keeps the draws for the lifetime of the process.
+type MemoryDrawRepository struct {
+	mutex sync.Mutex
+	draws map[string]drawRecord
+}
+
+func NewMemoryDrawRepository() *MemoryDrawRepository {
+	return &MemoryDrawRepository{draws: make(map[string]drawRecord)}
+}
+
+func (r *MemoryDrawRepository) Save(draw *entity.DrawCommitment) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.draws[draw.ID()].Version != draw.Version() {
+		return fmt.Errorf("storing draw `%s` error: %w", draw.ID(), repository.ErrVersionConflict)
+	}
+
+	record := newDrawRecord(draw)
+	record.Version++
+	r.draws[draw.ID()] = record
+	draw.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r *MemoryDrawRepository) Get(id string) (*entity.DrawCommitment, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record, ok := r.draws[id]
+	if !ok {
+		return nil, fmt.Errorf("draw `%s` error: %w", id, repository.ErrDrawNotFound)
+	}
+
+	return record.toEntity(), nil
+}
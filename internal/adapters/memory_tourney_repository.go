@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+// MemoryTourneyRepository keeps the tourneys for the lifetime of the process.
+type MemoryTourneyRepository struct {
+	mutex    sync.RWMutex
+	tourneys map[string]tourneyRecord
+}
+
+func NewMemoryTourneyRepository() *MemoryTourneyRepository {
+	return &MemoryTourneyRepository{tourneys: make(map[string]tourneyRecord)}
+}
+
+func (r *MemoryTourneyRepository) Save(tourney *entity.Tourney) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// a missing tourney is at version zero, so a deleted tourney can't be stored again by its old version.
+	if r.tourneys[tourney.ID()].Version != tourney.Version() {
+		return fmt.Errorf("storing tourney `%s` error: %w", tourney.ID(), repository.ErrVersionConflict)
+	}
+
+	record := newTourneyRecord(tourney)
+	record.Version++
+	r.tourneys[tourney.ID()] = record
+	tourney.AssignVersion(record.Version)
+
+	return nil
+}
+
+func (r *MemoryTourneyRepository) Get(id string) (*entity.Tourney, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	record, ok := r.tourneys[id]
+	if !ok {
+		return nil, fmt.Errorf("tourney `%s` error: %w", id, repository.ErrTourneyNotFound)
+	}
+
+	return record.toEntity(), nil
+}
+
+func (r *MemoryTourneyRepository) ListByUser(userID, cursor string, limit int) ([]*entity.Tourney, string, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	idsByKey := make(map[string]string)
+	keys := make([]string, 0)
+
+	for id, record := range r.tourneys {
+		tourney := record.toEntity()
+
+		for _, tourneyUserID := range tourney.UserIDs() {
+			if key := userIndexKey(tourney.CreatedAt(), id); tourneyUserID == userID && key > after {
+				idsByKey[key] = id
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	nextCursor := ""
+	if len(keys) > limit {
+		keys = keys[:limit]
+		nextCursor = encodeCursor(keys[limit-1])
+	}
+
+	tourneys := make([]*entity.Tourney, len(keys))
+	for i, key := range keys {
+		tourneys[i] = r.tourneys[idsByKey[key]].toEntity()
+	}
+
+	return tourneys, nextCursor, nil
+}
+
+func (r *MemoryTourneyRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.tourneys[id]; !ok {
+		return fmt.Errorf("tourney `%s` error: %w", id, repository.ErrTourneyNotFound)
+	}
+
+	delete(r.tourneys, id)
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package adapters
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+const userIndexKeySeparator = "/"
+
+// userIndexKey orders the tourneys of a user by creation, the ID orders the tourneys created at once.
+func userIndexKey(createdAt time.Time, id string) string {
+	return fmt.Sprintf("%020d%s%s", createdAt.UnixNano(), userIndexKeySeparator, id)
+}
+
+// encodeCursor hides the index key, so clients don't depend on it.
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor returns the index key the page starts after, empty for the first page.
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.Contains(string(key), userIndexKeySeparator) {
+		return "", fmt.Errorf("cursor `%s` error: %w", cursor, repository.ErrInvalidCursor)
+	}
+
+	return string(key), nil
+}
@@ -0,0 +1,291 @@
+package adapters
+
+import (
+	"time"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+// tourneyRecord is the stored form of a tourney, entities keep their state unexported.
+type tourneyRecord struct {
+	ID             string                `json:"id"`
+	CreatedAt      time.Time             `json:"created_at"`
+	GroupsCount    int                   `json:"groups_count"`
+	TeamsPerGroup  int                   `json:"teams_per_group"`
+	Seed           int64                 `json:"seed"`
+	Groups         []groupRecord         `json:"groups"`
+	ContestedTeams []contestedTeamRecord `json:"contested_teams,omitempty"`
+	Substitutions  []substitutionRecord  `json:"substitutions,omitempty"`
+	Knockout       *knockoutRecord       `json:"knockout,omitempty"`
+	Tiebreakers    []string              `json:"tiebreakers,omitempty"`
+	Pool           *poolRecord           `json:"pool,omitempty"`
+	Version        int                   `json:"version"`
+}
+
+type poolRecord struct {
+	MinRating float64 `json:"min_rating"`
+	MaxRating float64 `json:"max_rating"`
+	Size      int     `json:"size"`
+}
+
+type groupRecord struct {
+	Name      string           `json:"name"`
+	TeamSlots []slotRecord     `json:"team_slots"`
+	Matchdays []matchdayRecord `json:"matchdays,omitempty"`
+	Matches   []matchRecord    `json:"matches,omitempty"`
+}
+
+type slotRecord struct {
+	UserID string `json:"user_id"`
+	TeamID string `json:"team_id"`
+}
+
+type matchdayRecord struct {
+	Number       int      `json:"number"`
+	Fixtures     [][2]int `json:"fixtures"`
+	ByeSlotIndex int      `json:"bye_slot_index"`
+}
+
+type matchRecord struct {
+	HomeSlotIndex      int `json:"home_slot_index"`
+	AwaySlotIndex      int `json:"away_slot_index"`
+	HomeGoals          int `json:"home_goals"`
+	AwayGoals          int `json:"away_goals"`
+	HomeFairPlayPoints int `json:"home_fair_play_points,omitempty"`
+	AwayFairPlayPoints int `json:"away_fair_play_points,omitempty"`
+}
+
+type contestedTeamRecord struct {
+	TeamID      string   `json:"team_id"`
+	ClaimantIDs []string `json:"claimant_ids"`
+	OwnerIDs    []string `json:"owner_ids"`
+}
+
+type substitutionRecord struct {
+	UserID          string `json:"user_id"`
+	RequestedTeamID string `json:"requested_team_id"`
+	TeamID          string `json:"team_id"`
+}
+
+type knockoutRecord struct {
+	Rounds     []knockoutRoundRecord `json:"rounds"`
+	ThirdPlace *knockoutTieRecord    `json:"third_place,omitempty"`
+}
+
+type knockoutRoundRecord struct {
+	Name string              `json:"name"`
+	Ties []knockoutTieRecord `json:"ties"`
+}
+
+type knockoutTieRecord struct {
+	Number int                `json:"number"`
+	Home   knockoutSlotRecord `json:"home"`
+	Away   knockoutSlotRecord `json:"away"`
+}
+
+type knockoutSlotRecord struct {
+	GroupName string `json:"group_name,omitempty"`
+	Position  int    `json:"position,omitempty"`
+	Seed      int    `json:"seed,omitempty"`
+	TieNumber int    `json:"tie_number,omitempty"`
+	Loser     bool   `json:"loser,omitempty"`
+}
+
+func newTourneyRecord(tourney *entity.Tourney) tourneyRecord {
+	record := tourneyRecord{
+		ID:            tourney.ID(),
+		CreatedAt:     tourney.CreatedAt(),
+		GroupsCount:   tourney.GroupsCount(),
+		TeamsPerGroup: tourney.TeamsPerGroup(),
+		Seed:          tourney.Seed(),
+		Groups:        make([]groupRecord, len(tourney.Groups())),
+		Tiebreakers:   tourney.Tiebreakers(),
+		Version:       tourney.Version(),
+	}
+
+	for i, group := range tourney.Groups() {
+		record.Groups[i] = newGroupRecord(group)
+	}
+
+	if pool := tourney.Pool(); pool != nil {
+		record.Pool = &poolRecord{MinRating: pool.MinRating(), MaxRating: pool.MaxRating(), Size: pool.Size()}
+	}
+
+	for _, contestedTeam := range tourney.ContestedTeams() {
+		record.ContestedTeams = append(record.ContestedTeams, contestedTeamRecord{
+			TeamID:      contestedTeam.TeamID(),
+			ClaimantIDs: contestedTeam.ClaimantIDs(),
+			OwnerIDs:    contestedTeam.OwnerIDs(),
+		})
+	}
+
+	for _, substitution := range tourney.Substitutions() {
+		record.Substitutions = append(record.Substitutions, substitutionRecord{
+			UserID:          substitution.UserID(),
+			RequestedTeamID: substitution.RequestedTeamID(),
+			TeamID:          substitution.TeamID(),
+		})
+	}
+
+	if knockout := tourney.Knockout(); knockout != nil {
+		record.Knockout = &knockoutRecord{Rounds: make([]knockoutRoundRecord, len(knockout.Rounds()))}
+
+		for i, round := range knockout.Rounds() {
+			ties := make([]knockoutTieRecord, len(round.Ties()))
+			for j, tie := range round.Ties() {
+				ties[j] = newKnockoutTieRecord(tie)
+			}
+
+			record.Knockout.Rounds[i] = knockoutRoundRecord{Name: round.Name(), Ties: ties}
+		}
+
+		if knockout.ThirdPlace() != nil {
+			thirdPlace := newKnockoutTieRecord(knockout.ThirdPlace())
+			record.Knockout.ThirdPlace = &thirdPlace
+		}
+	}
+
+	return record
+}
+
+func newGroupRecord(group *entity.Group) groupRecord {
+	record := groupRecord{Name: group.Name(), TeamSlots: make([]slotRecord, group.Size())}
+
+	for i, slot := range group.TeamSlots() {
+		record.TeamSlots[i] = slotRecord{UserID: slot.UserID(), TeamID: slot.TeamID()}
+	}
+
+	for _, matchday := range group.Matchdays() {
+		fixtures := make([][2]int, len(matchday.Fixtures()))
+		for i, fixture := range matchday.Fixtures() {
+			fixtures[i] = [2]int{fixture.HomeSlotIndex(), fixture.AwaySlotIndex()}
+		}
+
+		record.Matchdays = append(record.Matchdays, matchdayRecord{
+			Number:       matchday.Number(),
+			Fixtures:     fixtures,
+			ByeSlotIndex: matchday.ByeSlotIndex(),
+		})
+	}
+
+	for _, match := range group.Matches() {
+		record.Matches = append(record.Matches, matchRecord{
+			HomeSlotIndex:      match.HomeSlotIndex(),
+			AwaySlotIndex:      match.AwaySlotIndex(),
+			HomeGoals:          match.HomeGoals(),
+			AwayGoals:          match.AwayGoals(),
+			HomeFairPlayPoints: match.HomeFairPlayPoints(),
+			AwayFairPlayPoints: match.AwayFairPlayPoints(),
+		})
+	}
+
+	return record
+}
+
+func newKnockoutTieRecord(tie *entity.KnockoutTie) knockoutTieRecord {
+	return knockoutTieRecord{
+		Number: tie.Number(),
+		Home:   newKnockoutSlotRecord(tie.Home()),
+		Away:   newKnockoutSlotRecord(tie.Away()),
+	}
+}
+
+func newKnockoutSlotRecord(slot *entity.KnockoutSlot) knockoutSlotRecord {
+	return knockoutSlotRecord{
+		GroupName: slot.GroupName(),
+		Position:  slot.Position(),
+		Seed:      slot.Seed(),
+		TieNumber: slot.TieNumber(),
+		Loser:     slot.Loser(),
+	}
+}
+
+func (r tourneyRecord) toEntity() *entity.Tourney {
+	groups := make([]*entity.Group, len(r.Groups))
+	for i, group := range r.Groups {
+		groups[i] = group.toEntity()
+	}
+
+	tourney := entity.NewTourney(r.ID, r.GroupsCount, r.TeamsPerGroup, r.Seed, groups)
+	tourney.AssignID(r.ID, r.CreatedAt)
+	tourney.AssignTiebreakers(r.Tiebreakers)
+	tourney.AssignVersion(r.Version)
+
+	if r.Pool != nil {
+		tourney.AssignPool(entity.NewTeamsPool(r.Pool.MinRating, r.Pool.MaxRating, r.Pool.Size))
+	}
+
+	for _, contestedTeam := range r.ContestedTeams {
+		tourney.AddContestedTeam(entity.NewContestedTeam(contestedTeam.TeamID, contestedTeam.ClaimantIDs, contestedTeam.OwnerIDs))
+	}
+
+	for _, substitution := range r.Substitutions {
+		tourney.AddSubstitution(entity.NewTeamSubstitution(substitution.UserID, substitution.RequestedTeamID, substitution.TeamID))
+	}
+
+	if r.Knockout != nil {
+		rounds := make([]*entity.KnockoutRound, len(r.Knockout.Rounds))
+
+		for i, round := range r.Knockout.Rounds {
+			ties := make([]*entity.KnockoutTie, len(round.Ties))
+			for j, tie := range round.Ties {
+				ties[j] = tie.toEntity()
+			}
+
+			rounds[i] = entity.NewKnockoutRound(round.Name, ties)
+		}
+
+		var thirdPlace *entity.KnockoutTie
+		if r.Knockout.ThirdPlace != nil {
+			thirdPlace = r.Knockout.ThirdPlace.toEntity()
+		}
+
+		tourney.AssignKnockout(entity.NewKnockoutStage(rounds, thirdPlace))
+	}
+
+	return tourney
+}
+
+func (r groupRecord) toEntity() *entity.Group {
+	slots := make([]*entity.GroupSlot, len(r.TeamSlots))
+	for i, slot := range r.TeamSlots {
+		slots[i] = entity.NewGroupSlot(slot.UserID, slot.TeamID)
+	}
+
+	group := entity.NewGroup(r.Name, slots)
+
+	matchdays := make([]*entity.Matchday, len(r.Matchdays))
+
+	for i, matchday := range r.Matchdays {
+		fixtures := make([]*entity.Fixture, len(matchday.Fixtures))
+		for j, fixture := range matchday.Fixtures {
+			fixtures[j] = entity.NewFixture(fixture[0], fixture[1])
+		}
+
+		matchdays[i] = entity.NewMatchday(matchday.Number, fixtures, matchday.ByeSlotIndex)
+	}
+
+	group.AssignMatchdays(matchdays)
+
+	for _, match := range r.Matches {
+		group.AddMatch(entity.NewMatch(match.HomeSlotIndex, match.AwaySlotIndex, match.HomeGoals, match.AwayGoals).
+			WithFairPlayPoints(match.HomeFairPlayPoints, match.AwayFairPlayPoints))
+	}
+
+	return group
+}
+
+func (r knockoutTieRecord) toEntity() *entity.KnockoutTie {
+	return entity.NewKnockoutTie(r.Number, r.Home.toEntity(), r.Away.toEntity())
+}
+
+func (r knockoutSlotRecord) toEntity() *entity.KnockoutSlot {
+	switch {
+	case r.TieNumber != 0 && r.Loser:
+		return entity.NewLoserSlot(r.TieNumber)
+	case r.TieNumber != 0:
+		return entity.NewWinnerSlot(r.TieNumber)
+	default:
+		return entity.NewQualifierSlot(r.GroupName, r.Position, r.Seed)
+	}
+}
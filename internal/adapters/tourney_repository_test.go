@@ -0,0 +1,211 @@
+package adapters_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+func TestTourneyRepositories(t *testing.T) {
+	t.Parallel()
+
+	boltRepository, err := adapters.NewBoltTourneyRepository(filepath.Join(t.TempDir(), "tourneys.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, boltRepository.Close())
+	})
+
+	for name, tourneys := range map[string]repository.TourneyRepository{
+		"memory": adapters.NewMemoryTourneyRepository(),
+		"bolt":   boltRepository,
+	} {
+		testTourneyRepository(t, name, tourneys)
+	}
+}
+
+// TestDynamoDBTourneyRepository runs against DynamoDB Local, e.g. `docker run -p 8000:8000 amazon/dynamodb-local`
+// with DYNAMODB_LOCAL_ENDPOINT=http://localhost:8000.
+func TestDynamoDBTourneyRepository(t *testing.T) {
+	t.Parallel()
+
+	endpoint, varExists := os.LookupEnv("DYNAMODB_LOCAL_ENDPOINT")
+	if !varExists {
+		t.Skip("DYNAMODB_LOCAL_ENDPOINT isn't set")
+	}
+
+	conf := aws.NewConfig().
+		WithEndpoint(endpoint).
+		WithRegion("us-east-1").
+		WithCredentials(credentials.NewStaticCredentials("local", "local", ""))
+	client := dynamodb.New(session.Must(session.NewSession()), conf)
+	table := fmt.Sprintf("tourneys-%d", time.Now().UnixNano())
+
+	tourneys := adapters.NewDynamoDBTourneyRepository(client, table)
+	require.NoError(t, tourneys.CreateTable())
+	t.Cleanup(func() {
+		_, err := client.DeleteTable(&dynamodb.DeleteTableInput{TableName: aws.String(table)})
+		assert.NoError(t, err)
+	})
+
+	testTourneyRepository(t, "dynamodb", tourneys)
+	testDrawRepository(t, "dynamodb", adapters.NewDynamoDBDrawRepository(client, table))
+}
+
+func testTourneyRepository(t *testing.T, name string, tourneys repository.TourneyRepository) {
+	t.Helper()
+
+	tourney := newTourney()
+
+	require.NoError(t, tourneys.Save(tourney), name)
+
+	stored, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err, name)
+	assert.Equal(t, tourney, stored, name)
+
+	_, err = tourneys.Get("unknown")
+	assert.ErrorIs(t, err, repository.ErrTourneyNotFound, name)
+
+	testVersionConflicts(t, name, tourneys)
+	testListAndDelete(t, name, tourneys)
+}
+
+// testVersionConflicts stores over the loaded version only: a stale copy and a copy of a deleted tourney are refused.
+func testVersionConflicts(t *testing.T, name string, tourneys repository.TourneyRepository) {
+	t.Helper()
+
+	tourney := newTourney()
+	tourney.AssignID("f0", tourney.CreatedAt())
+	require.NoError(t, tourneys.Save(tourney), name)
+
+	first, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err, name)
+	second, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err, name)
+
+	created := newTourney()
+	created.AssignID(tourney.ID(), tourney.CreatedAt())
+
+	require.NoError(t, tourneys.Save(first), name)
+	assert.ErrorIs(t, tourneys.Save(second), repository.ErrVersionConflict, name)
+	assert.ErrorIs(t, tourneys.Save(created), repository.ErrVersionConflict, name)
+
+	stored, err := tourneys.Get(tourney.ID())
+	require.NoError(t, err, name)
+	assert.Equal(t, first.Version(), stored.Version(), name)
+
+	require.NoError(t, tourneys.Delete(tourney.ID()), name)
+	assert.ErrorIs(t, tourneys.Save(first), repository.ErrVersionConflict, name)
+
+	_, err = tourneys.Get(tourney.ID())
+	assert.ErrorIs(t, err, repository.ErrTourneyNotFound, name)
+}
+
+func testListAndDelete(t *testing.T, name string, tourneys repository.TourneyRepository) {
+	t.Helper()
+
+	createdAt := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	var ids []string
+
+	// every tourney has slots of user-1 and user-2, a5 and b4 are created at once.
+	for _, stored := range []struct {
+		id      string
+		minutes int
+	}{{id: "e1"}, {id: "d2", minutes: 1}, {id: "c3", minutes: 2}, {id: "b4", minutes: 3}, {id: "a5", minutes: 3}} {
+		tourney := newTourney()
+		tourney.AssignID(stored.id, createdAt.Add(time.Duration(stored.minutes)*time.Minute))
+		require.NoError(t, tourneys.Save(tourney), name)
+
+		ids = append(ids, stored.id)
+	}
+
+	var (
+		listed []string
+		cursor string
+	)
+
+	for pages := 0; pages < 3; pages++ {
+		page, nextCursor, err := tourneys.ListByUser("user-1", cursor, 2)
+		require.NoError(t, err, name)
+
+		for _, tourney := range page {
+			listed = append(listed, tourney.ID())
+		}
+
+		if cursor = nextCursor; cursor == "" {
+			break
+		}
+	}
+
+	assert.Empty(t, cursor, name)
+	assert.Equal(t, []string{"3f2a", "e1", "d2", "c3", "a5", "b4"}, listed, name)
+
+	require.NoError(t, tourneys.Delete(ids[1]), name)
+	assert.ErrorIs(t, tourneys.Delete(ids[1]), repository.ErrTourneyNotFound, name)
+
+	page, nextCursor, err := tourneys.ListByUser("user-2", "", 10)
+	require.NoError(t, err, name)
+	assert.Len(t, page, 5, name)
+	assert.Empty(t, nextCursor, name)
+
+	page, _, err = tourneys.ListByUser("user-3", "", 10)
+	require.NoError(t, err, name)
+	assert.Empty(t, page, name)
+
+	_, _, err = tourneys.ListByUser("user-1", "not a cursor", 10)
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor, name)
+}
+
+func newTourney() *entity.Tourney {
+	group := entity.NewGroup("A", []*entity.GroupSlot{
+		entity.NewGroupSlot("user-1", "team-1"),
+		entity.NewGroupSlot("user-2", "team-2"),
+		entity.NewGroupSlot("user-1", "team-3"),
+	})
+	group.AssignMatchdays([]*entity.Matchday{
+		entity.NewMatchday(1, []*entity.Fixture{entity.NewFixture(0, 1)}, 2),
+		entity.NewMatchday(2, []*entity.Fixture{entity.NewFixture(2, 0)}, 1),
+		entity.NewMatchday(3, []*entity.Fixture{entity.NewFixture(1, 2)}, 0),
+	})
+	group.AddMatch(entity.NewMatch(0, 1, 2, 1).WithFairPlayPoints(1, 3))
+
+	otherGroup := entity.NewGroup("B", []*entity.GroupSlot{
+		entity.NewGroupSlot("user-2", "team-4"),
+		entity.NewGroupSlot("user-1", "team-5"),
+	})
+	otherGroup.AssignMatchdays([]*entity.Matchday{
+		entity.NewMatchday(1, []*entity.Fixture{entity.NewFixture(0, 1)}, entity.NoBye),
+	})
+
+	tourney := entity.NewTourney("", 2, 0, 42, []*entity.Group{group, otherGroup})
+	tourney.AssignID("3f2a", time.Date(2022, 1, 4, 13, 47, 30, 0, time.UTC))
+	tourney.AssignTiebreakers([]string{"points", "lots"})
+	tourney.AddContestedTeam(entity.NewContestedTeam("team-1", []string{"user-1", "user-2"}, []string{"user-1"}))
+	tourney.AddSubstitution(entity.NewTeamSubstitution("user-2", "team-9", "team-2"))
+	tourney.AssignKnockout(entity.NewKnockoutStage(
+		[]*entity.KnockoutRound{
+			entity.NewKnockoutRound("Semi-finals", []*entity.KnockoutTie{
+				entity.NewKnockoutTie(1, entity.NewQualifierSlot("A", 1, 1), entity.NewQualifierSlot("B", 2, 4)),
+				entity.NewKnockoutTie(2, entity.NewQualifierSlot("B", 1, 2), entity.NewQualifierSlot("A", 2, 3)),
+			}),
+			entity.NewKnockoutRound("Final", []*entity.KnockoutTie{
+				entity.NewKnockoutTie(4, entity.NewWinnerSlot(1), entity.NewWinnerSlot(2)),
+			}),
+		},
+		entity.NewKnockoutTie(3, entity.NewLoserSlot(1), entity.NewLoserSlot(2)),
+	))
+
+	return tourney
+}
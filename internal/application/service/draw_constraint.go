@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+const (
+	ConstraintPots             = "pots"
+	ConstraintLeagueProtection = "league_protection"
+	ConstraintUserSpread       = "user_spread"
+	ConstraintRivalSeparation  = "rival_separation"
+)
+
+var (
+	errConstraint = errors.New("unknown constraint")
+	errRivals     = errors.New("rivals error")
+)
+
+// Constraint limits which slots can share a group, the solver combines all constraints of a draw.
+type Constraint interface {
+	Name() string
+	// Allows evaluates the partial assignment: whether the slot can join the group holding groupSlots.
+	Allows(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool
+	// Explain tells why the slot can't join the group holding groupSlots.
+	Explain(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) string
+}
+
+// InfeasibleError tells which constraint made the draw impossible.
+type InfeasibleError struct {
+	Constraint string
+	Reason     string
+}
+
+func (e *InfeasibleError) Error() string {
+	if e.Constraint == "" {
+		return fmt.Sprintf("%s: %s", errDrawUnsatisfiable, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: constraint `%s`: %s", errDrawUnsatisfiable, e.Constraint, e.Reason)
+}
+
+func (e *InfeasibleError) Unwrap() error {
+	return errDrawUnsatisfiable
+}
+
+func validateConstraintNames(names []string) error {
+	for i, name := range names {
+		switch name {
+		case ConstraintPots, ConstraintLeagueProtection, ConstraintUserSpread, ConstraintRivalSeparation:
+		default:
+			return settingsError(fmt.Sprintf("constraints[%d]", i), errConstraint, "`%s` is unknown", name)
+		}
+	}
+
+	return nil
+}
+
+func validateRivals(rivals [][]string) error {
+	for i, teamIDs := range rivals {
+		unique := make(map[string]bool, len(teamIDs))
+		for _, teamID := range teamIDs {
+			unique[teamID] = true
+		}
+
+		if len(unique) < 2 {
+			return settingsError(fmt.Sprintf("rivals[%d]", i), errRivals, "needs at least two different teams")
+		}
+	}
+
+	return nil
+}
+
+// firstViolated returns the first constraint not allowing the slot to join the group, nil when all allow it.
+func firstViolated(constraints []Constraint, groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) Constraint {
+	for _, constraint := range constraints {
+		if !constraint.Allows(groupSlots, slot) {
+			return constraint
+		}
+	}
+
+	return nil
+}
+
+func allowed(constraints []Constraint, groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool {
+	return firstViolated(constraints, groupSlots, slot) == nil
+}
+
+type potsConstraint struct {
+	potBySlot map[*entity.GroupSlot]int
+}
+
+func (c potsConstraint) Name() string {
+	return ConstraintPots
+}
+
+func (c potsConstraint) Allows(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool {
+	return c.samePotSlot(groupSlots, slot) == nil
+}
+
+func (c potsConstraint) Explain(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) string {
+	if other := c.samePotSlot(groupSlots, slot); other != nil {
+		return fmt.Sprintf("team `%s` shares pot %d with team `%s`", slot.TeamID(), c.potBySlot[slot]+1, other.TeamID())
+	}
+
+	return ""
+}
+
+func (c potsConstraint) samePotSlot(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) *entity.GroupSlot {
+	for _, groupSlot := range groupSlots {
+		if c.potBySlot[groupSlot] == c.potBySlot[slot] {
+			return groupSlot
+		}
+	}
+
+	return nil
+}
+
+type leagueProtectionConstraint struct {
+	leagueKey func(slot *entity.GroupSlot) string
+}
+
+// newLeagueProtectionConstraint fails fast when some league has more teams than there are groups,
+// the teams with an empty key aren't protected.
+func newLeagueProtectionConstraint(
+	slots []*entity.GroupSlot,
+	groupsCount int,
+	leagueKey func(slot *entity.GroupSlot) string,
+) (Constraint, error) {
+	slotsCountByKey := make(map[string]int)
+	for _, slot := range slots {
+		if key := leagueKey(slot); key != "" {
+			slotsCountByKey[key]++
+		}
+	}
+
+	keys := make([]string, 0, len(slotsCountByKey))
+	for key := range slotsCountByKey {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if slotsCountByKey[key] > groupsCount {
+			return nil, &InfeasibleError{
+				Constraint: ConstraintLeagueProtection,
+				Reason:     fmt.Sprintf("`%s` has %d teams, only %d groups", key, slotsCountByKey[key], groupsCount),
+			}
+		}
+	}
+
+	return leagueProtectionConstraint{leagueKey: leagueKey}, nil
+}
+
+func (c leagueProtectionConstraint) Name() string {
+	return ConstraintLeagueProtection
+}
+
+func (c leagueProtectionConstraint) Allows(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool {
+	key := c.leagueKey(slot)
+	if key == "" {
+		return true
+	}
+
+	for _, groupSlot := range groupSlots {
+		if c.leagueKey(groupSlot) == key {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c leagueProtectionConstraint) Explain(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) string {
+	if c.Allows(groupSlots, slot) {
+		return ""
+	}
+
+	return fmt.Sprintf("the group already has a team of `%s`", c.leagueKey(slot))
+}
+
+type userSpreadConstraint struct {
+	slotsCapByUserID map[string]int
+}
+
+// newUserSpreadConstraint resolves how many slots of every user a group may have. Without an explicit cap
+// the slots are spread as evenly as possible.
+func newUserSpreadConstraint(slotsCountByUserID map[string]int, groupsCount, maxUserSlotsPerGroup int) (Constraint, error) {
+	userIDs := make([]string, 0, len(slotsCountByUserID))
+	for userID := range slotsCountByUserID {
+		userIDs = append(userIDs, userID)
+	}
+
+	sort.Strings(userIDs)
+
+	slotsCapByUserID := make(map[string]int, len(slotsCountByUserID))
+
+	for _, userID := range userIDs {
+		slotsCount := slotsCountByUserID[userID]
+
+		if maxUserSlotsPerGroup == 0 {
+			slotsCapByUserID[userID] = (slotsCount + groupsCount - 1) / groupsCount
+
+			continue
+		}
+
+		if slotsCount > groupsCount*maxUserSlotsPerGroup {
+			return nil, &InfeasibleError{
+				Constraint: ConstraintUserSpread,
+				Reason: fmt.Sprintf(
+					"user `%s` has %d teams, %d groups allow at most %d",
+					userID, slotsCount, groupsCount, groupsCount*maxUserSlotsPerGroup,
+				),
+			}
+		}
+
+		slotsCapByUserID[userID] = maxUserSlotsPerGroup
+	}
+
+	return userSpreadConstraint{slotsCapByUserID: slotsCapByUserID}, nil
+}
+
+func (c userSpreadConstraint) Name() string {
+	return ConstraintUserSpread
+}
+
+func (c userSpreadConstraint) Allows(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool {
+	return c.userSlotsCount(groupSlots, slot.UserID()) < c.slotsCapByUserID[slot.UserID()]
+}
+
+func (c userSpreadConstraint) Explain(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) string {
+	if c.Allows(groupSlots, slot) {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"user `%s` already has %d teams in the group", slot.UserID(), c.userSlotsCount(groupSlots, slot.UserID()),
+	)
+}
+
+func (c userSpreadConstraint) userSlotsCount(groupSlots []*entity.GroupSlot, userID string) int {
+	userSlotsCount := 0
+
+	for _, groupSlot := range groupSlots {
+		if groupSlot.UserID() == userID {
+			userSlotsCount++
+		}
+	}
+
+	return userSlotsCount
+}
+
+type rivalSeparationConstraint struct {
+	rivalsByTeamID map[string]map[string]bool
+}
+
+// newRivalSeparationConstraint keeps rival teams in different groups, rivals missing in the draw are ignored.
+func newRivalSeparationConstraint(rivals [][]string, slots []*entity.GroupSlot, groupsCount int) (Constraint, error) {
+	drawnTeamIDs := make(map[string]bool, len(slots))
+	for _, slot := range slots {
+		drawnTeamIDs[slot.TeamID()] = true
+	}
+
+	rivalsByTeamID := make(map[string]map[string]bool)
+
+	for _, teamIDs := range rivals {
+		drawn := make(map[string]bool, len(teamIDs))
+
+		for _, teamID := range teamIDs {
+			if drawnTeamIDs[teamID] {
+				drawn[teamID] = true
+			}
+		}
+
+		if len(drawn) > groupsCount {
+			return nil, &InfeasibleError{
+				Constraint: ConstraintRivalSeparation,
+				Reason:     fmt.Sprintf("%d rival teams %v, only %d groups", len(drawn), teamIDs, groupsCount),
+			}
+		}
+
+		for teamID := range drawn {
+			if rivalsByTeamID[teamID] == nil {
+				rivalsByTeamID[teamID] = make(map[string]bool)
+			}
+
+			for rivalID := range drawn {
+				if rivalID != teamID {
+					rivalsByTeamID[teamID][rivalID] = true
+				}
+			}
+		}
+	}
+
+	return rivalSeparationConstraint{rivalsByTeamID: rivalsByTeamID}, nil
+}
+
+func (c rivalSeparationConstraint) Name() string {
+	return ConstraintRivalSeparation
+}
+
+func (c rivalSeparationConstraint) Allows(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) bool {
+	return c.rivalSlot(groupSlots, slot) == nil
+}
+
+func (c rivalSeparationConstraint) Explain(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) string {
+	if rival := c.rivalSlot(groupSlots, slot); rival != nil {
+		return fmt.Sprintf("team `%s` is a rival of team `%s`", slot.TeamID(), rival.TeamID())
+	}
+
+	return ""
+}
+
+func (c rivalSeparationConstraint) rivalSlot(groupSlots []*entity.GroupSlot, slot *entity.GroupSlot) *entity.GroupSlot {
+	rivals := c.rivalsByTeamID[slot.TeamID()]
+	if len(rivals) == 0 {
+		return nil
+	}
+
+	for _, groupSlot := range groupSlots {
+		if rivals[groupSlot.TeamID()] {
+			return groupSlot
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/twizar/common/pkg/dto"
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+const (
+	ObjectiveGroupRatingVariance = "group_rating_variance"
+	ObjectiveUserFairness        = "user_fairness"
+	ObjectiveLeagueCollisions    = "league_collisions"
+)
+
+type UserRating struct {
+	UserID        string
+	TeamsCount    int
+	RatingTotal   float64
+	RatingAverage float64
+}
+
+type GroupQuality struct {
+	Name            string
+	RatingMean      float64
+	RatingVariance  float64
+	SameLeaguePairs int
+	SameUserPairs   int
+}
+
+// TeamsPool is the rating range the teams were drawn from and the count of teams it yielded.
+type TeamsPool struct {
+	MinRating      float64
+	MaxRating      float64
+	AvailableTeams int
+}
+
+type DrawReport struct {
+	Pool            *TeamsPool
+	Groups          []GroupQuality
+	Users           []UserRating
+	SameLeaguePairs int
+	SameUserPairs   int
+}
+
+func NewDrawReport(tourney *entity.Tourney, teamsByID map[string]dto.Team) *DrawReport {
+	report := &DrawReport{
+		Groups: make([]GroupQuality, len(tourney.Groups())),
+		Users:  userRatings(tourney.Groups(), teamsByID),
+	}
+
+	if pool := tourney.Pool(); pool != nil {
+		report.Pool = &TeamsPool{MinRating: pool.MinRating(), MaxRating: pool.MaxRating(), AvailableTeams: pool.Size()}
+	}
+
+	for i, group := range tourney.Groups() {
+		report.Groups[i] = groupQuality(group, teamsByID)
+		report.SameLeaguePairs += report.Groups[i].SameLeaguePairs
+		report.SameUserPairs += report.Groups[i].SameUserPairs
+	}
+
+	return report
+}
+
+// Score rates the draw by the objective, the lower score the better draw.
+func (r DrawReport) Score(objective string) float64 {
+	switch objective {
+	case ObjectiveUserFairness:
+		averages := make([]float64, len(r.Users))
+		for i, user := range r.Users {
+			averages[i] = user.RatingAverage
+		}
+
+		return variance(averages)
+	case ObjectiveLeagueCollisions:
+		return float64(r.SameLeaguePairs)
+	default:
+		means := make([]float64, len(r.Groups))
+		for i, group := range r.Groups {
+			means[i] = group.RatingMean
+		}
+
+		return variance(means)
+	}
+}
+
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+
+	mean /= float64(len(values))
+
+	result := 0.0
+	for _, value := range values {
+		result += sqr(value - mean)
+	}
+
+	return result / float64(len(values))
+}
+
+func groupQuality(group *entity.Group, teamsByID map[string]dto.Team) GroupQuality {
+	quality := GroupQuality{Name: group.Name()}
+	slots := group.TeamSlots()
+
+	if len(slots) == 0 {
+		return quality
+	}
+
+	for i, slot := range slots {
+		quality.RatingMean += teamsByID[slot.TeamID()].Rating
+
+		for _, other := range slots[i+1:] {
+			if teamsByID[slot.TeamID()].League == teamsByID[other.TeamID()].League {
+				quality.SameLeaguePairs++
+			}
+
+			if slot.UserID() == other.UserID() {
+				quality.SameUserPairs++
+			}
+		}
+	}
+
+	ratings := make([]float64, len(slots))
+	for i, slot := range slots {
+		ratings[i] = teamsByID[slot.TeamID()].Rating
+	}
+
+	quality.RatingMean /= float64(len(slots))
+	quality.RatingVariance = variance(ratings)
+
+	return quality
+}
+
+func userRatings(groups []*entity.Group, teamsByID map[string]dto.Team) []UserRating {
+	ratingsByUserID := make(map[string]*UserRating)
+
+	for _, group := range groups {
+		for _, slot := range group.TeamSlots() {
+			rating, ok := ratingsByUserID[slot.UserID()]
+			if !ok {
+				rating = &UserRating{UserID: slot.UserID()}
+				ratingsByUserID[slot.UserID()] = rating
+			}
+
+			rating.TeamsCount++
+			rating.RatingTotal += teamsByID[slot.TeamID()].Rating
+		}
+	}
+
+	ratings := make([]UserRating, 0, len(ratingsByUserID))
+
+	for _, rating := range ratingsByUserID {
+		rating.RatingAverage = rating.RatingTotal / float64(rating.TeamsCount)
+		ratings = append(ratings, *rating)
+	}
+
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].UserID < ratings[j].UserID
+	})
+
+	return ratings
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/twizar/common/pkg/dto"
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+const (
+	LeagueProtectionNone    = "none"
+	LeagueProtectionLeague  = "league"
+	LeagueProtectionCountry = "country"
+
+	solverStepsBudget = 2_000
+	solverRestarts    = 20
+	// the last attempt searches longer before the solver gives up.
+	solverFinalStepsBudget = 50_000
+)
+
+var (
+	errLeagueProtection  = errors.New("unknown league protection")
+	errDrawUnsatisfiable = errors.New("draw constraints can't be satisfied")
+	errUserSpread        = errors.New("user spread error")
+	errSearchExhausted   = errors.New("draw search exhausted")
+)
+
+// SearchExhaustedError tells the solver gave up before it found a draw or proved there is none,
+// another seed may find a draw.
+type SearchExhaustedError struct {
+	Attempts int
+}
+
+func (e *SearchExhaustedError) Error() string {
+	return fmt.Sprintf("%s: no draw found within %d attempts, another seed may find one", errSearchExhausted, e.Attempts)
+}
+
+func (e *SearchExhaustedError) Unwrap() error {
+	return errSearchExhausted
+}
+
+// leagueCountries maps the leagues of the teams service to their countries, a league missing here is
+// protected as a country of its own. The club competitions and the leagues of special teams have no country,
+// so their teams aren't protected by country.
+var leagueCountries = map[string]string{
+	"Argentina Primera División (1)":    "Argentina",
+	"Australia A-League (1)":            "Australia",
+	"Austria Bundesliga (1)":            "Austria",
+	"Belgium Pro League (1)":            "Belgium",
+	"Brazil Serie A (1)":                "Brazil",
+	"China Super League (1)":            "China",
+	"Denmark Superliga (1)":             "Denmark",
+	"England Premier League (1)":        "England",
+	"England Championship (2)":          "England",
+	"England League One (3)":            "England",
+	"England League Two (4)":            "England",
+	"France Ligue 1 (1)":                "France",
+	"France Ligue 2 (2)":                "France",
+	"Germany 1. Bundesliga (1)":         "Germany",
+	"Germany 2. Bundesliga (2)":         "Germany",
+	"Germany 3. Liga (3)":               "Germany",
+	"Holland Eredivisie (1)":            "Netherlands",
+	"Indian Super League (1)":           "India",
+	"Italy Serie A (1)":                 "Italy",
+	"Japan J1 League (1)":               "Japan",
+	"Korea K League 1 (1)":              "Korea",
+	"Mexico Liga MX (1)":                "Mexico",
+	"Norway Eliteserien (1)":            "Norway",
+	"Poland Ekstraklasa (1)":            "Poland",
+	"Portugal Primeira Liga (1)":        "Portugal",
+	"Rep. Ireland Premier Division (1)": "Republic of Ireland",
+	"Romania Liga I (1)":                "Romania",
+	"Saudi Pro League (1)":              "Saudi Arabia",
+	"Scotland Premiership (1)":          "Scotland",
+	"Spain Primera División (1)":        "Spain",
+	"Spain Segunda División (2)":        "Spain",
+	"Sweden Allsvenskan (1)":            "Sweden",
+	"Switzerland Super League (1)":      "Switzerland",
+	"Turkey Süper Lig (1)":              "Turkey",
+	"USA Major League Soccer (1)":       "USA",
+	"CONMEBOL Libertadores":             "",
+	"CONMEBOL Sudamericana":             "",
+	"Rest of World":                     "",
+	"Free Agents League":                "",
+	"Create Player League":              "",
+}
+
+type drawSolver struct {
+	rnd         *rand.Rand
+	groups      []*entity.Group
+	slots       []*entity.GroupSlot
+	constraints []Constraint
+	placed      []bool
+	placement   [][]*entity.GroupSlot
+	steps       int
+	budget      int
+	conflicts   *solverConflicts
+}
+
+// solverConflicts counts dead ends of the search by the constraint which caused them.
+type solverConflicts struct {
+	countByConstraint  map[string]int
+	reasonByConstraint map[string]string
+}
+
+// placeSlots assigns slots to groups backtracking when a slot can't be placed by the constraints. The most
+// constrained slot is placed first, the given order breaks ties and defines the order of slots in a group.
+func placeSlots(rnd *rand.Rand, groups []*entity.Group, slots []*entity.GroupSlot, constraints []Constraint) error {
+	var solver *drawSolver
+
+	conflicts := &solverConflicts{countByConstraint: make(map[string]int), reasonByConstraint: make(map[string]string)}
+
+	// a search stuck in a bad branch is restarted with another random order of groups.
+	for attempt := 0; ; attempt++ {
+		budget := solverStepsBudget
+		if attempt == solverRestarts {
+			budget = solverFinalStepsBudget
+		}
+
+		solver = &drawSolver{
+			rnd:         rnd,
+			groups:      groups,
+			slots:       slots,
+			constraints: constraints,
+			placed:      make([]bool, len(slots)),
+			placement:   make([][]*entity.GroupSlot, len(groups)),
+			budget:      budget,
+			conflicts:   conflicts,
+		}
+
+		if solver.place(len(slots)) {
+			break
+		}
+
+		// only a search which ran out of branches before its budget proves there is no draw.
+		if solver.steps < budget {
+			return conflicts.infeasibleError("no draw exists")
+		}
+
+		if attempt == solverRestarts {
+			return &SearchExhaustedError{Attempts: solverRestarts + 1}
+		}
+	}
+
+	orderBySlot := make(map[*entity.GroupSlot]int, len(slots))
+	for i, slot := range slots {
+		orderBySlot[slot] = i
+	}
+
+	for i, groupSlots := range solver.placement {
+		sort.Slice(groupSlots, func(a, b int) bool {
+			return orderBySlot[groupSlots[a]] < orderBySlot[groupSlots[b]]
+		})
+
+		for slotIndex, slot := range groupSlots {
+			groups[i].AssignSlot(slotIndex, slot)
+		}
+	}
+
+	return nil
+}
+
+func (ds *drawSolver) place(unplacedCount int) bool {
+	if unplacedCount == 0 {
+		return true
+	}
+
+	if ds.steps >= ds.budget {
+		return false
+	}
+
+	ds.steps++
+
+	slotIndex, groupIndexes := ds.mostConstrainedSlot()
+	if len(groupIndexes) == 0 {
+		ds.recordConflict(ds.slots[slotIndex])
+
+		return false
+	}
+
+	slot := ds.slots[slotIndex]
+	ds.placed[slotIndex] = true
+
+	for _, groupIndex := range groupIndexes {
+		ds.placement[groupIndex] = append(ds.placement[groupIndex], slot)
+
+		if ds.place(unplacedCount - 1) {
+			return true
+		}
+
+		ds.placement[groupIndex] = ds.placement[groupIndex][:len(ds.placement[groupIndex])-1]
+	}
+
+	ds.placed[slotIndex] = false
+
+	return false
+}
+
+// mostConstrainedSlot finds the unplaced slot with the fewest fitting groups. The groups are ordered
+// by their fill level, empty groups of the same size are interchangeable, so only one of them is kept.
+func (ds *drawSolver) mostConstrainedSlot() (slotIndex int, groupIndexes []int) {
+	groupsOrder := ds.rnd.Perm(len(ds.groups))
+	sort.SliceStable(groupsOrder, func(i, j int) bool {
+		return len(ds.placement[groupsOrder[i]]) < len(ds.placement[groupsOrder[j]])
+	})
+
+	slotIndex = -1
+
+	for i, slot := range ds.slots {
+		if ds.placed[i] {
+			continue
+		}
+
+		var fitting []int
+
+		emptyGroupSizes := make(map[int]bool)
+
+		for _, groupIndex := range groupsOrder {
+			if !ds.fits(groupIndex, slot) {
+				continue
+			}
+
+			if size := len(ds.groups[groupIndex].TeamSlots()); len(ds.placement[groupIndex]) == 0 {
+				if emptyGroupSizes[size] {
+					continue
+				}
+
+				emptyGroupSizes[size] = true
+			}
+
+			fitting = append(fitting, groupIndex)
+		}
+
+		if slotIndex == -1 || len(fitting) < len(groupIndexes) {
+			slotIndex, groupIndexes = i, fitting
+		}
+
+		if len(groupIndexes) == 0 {
+			break
+		}
+	}
+
+	return slotIndex, groupIndexes
+}
+
+func (ds *drawSolver) fits(groupIndex int, slot *entity.GroupSlot) bool {
+	if len(ds.placement[groupIndex]) == len(ds.groups[groupIndex].TeamSlots()) {
+		return false
+	}
+
+	return allowed(ds.constraints, ds.placement[groupIndex], slot)
+}
+
+// recordConflict blames the constraints keeping the slot out of the groups with free places.
+func (ds *drawSolver) recordConflict(slot *entity.GroupSlot) {
+	for groupIndex, groupSlots := range ds.placement {
+		if len(groupSlots) == len(ds.groups[groupIndex].TeamSlots()) {
+			continue
+		}
+
+		if constraint := firstViolated(ds.constraints, groupSlots, slot); constraint != nil {
+			ds.conflicts.countByConstraint[constraint.Name()]++
+			ds.conflicts.reasonByConstraint[constraint.Name()] = constraint.Explain(groupSlots, slot)
+		}
+	}
+}
+
+// infeasibleError names the constraint which caused the most dead ends.
+func (sc solverConflicts) infeasibleError(reason string) error {
+	names := make([]string, 0, len(sc.countByConstraint))
+	for name := range sc.countByConstraint {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	infeasibleError := &InfeasibleError{Reason: reason}
+
+	for _, name := range names {
+		if infeasibleError.Constraint == "" || sc.countByConstraint[name] > sc.countByConstraint[infeasibleError.Constraint] {
+			infeasibleError.Constraint = name
+		}
+	}
+
+	if infeasibleError.Constraint != "" {
+		infeasibleError.Reason = fmt.Sprintf("%s, e.g. %s", reason, sc.reasonByConstraint[infeasibleError.Constraint])
+	}
+
+	return infeasibleError
+}
+
+// rebalanceGroups swaps slots between random groups while the swaps obey the constraints and decrease
+// the variance of the groups' average ratings.
+func rebalanceGroups(
+	rnd *rand.Rand,
+	groups []*entity.Group,
+	constraints []Constraint,
+	teamsByID map[string]dto.Team,
+	iterations int,
+) {
+	if len(groups) < 2 {
+		return
+	}
+
+	ratingTotals := make([]float64, len(groups))
+
+	for i, group := range groups {
+		for _, slot := range group.TeamSlots() {
+			ratingTotals[i] += teamsByID[slot.TeamID()].Rating
+		}
+	}
+
+	means := func() []float64 {
+		result := make([]float64, len(groups))
+		for i, group := range groups {
+			result[i] = ratingTotals[i] / float64(len(group.TeamSlots()))
+		}
+
+		return result
+	}
+
+	score := variance(means())
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		a, b := rnd.Intn(len(groups)), rnd.Intn(len(groups)-1)
+		if b >= a {
+			b++
+		}
+
+		slotsA, slotsB := groups[a].TeamSlots(), groups[b].TeamSlots()
+		i, j := rnd.Intn(len(slotsA)), rnd.Intn(len(slotsB))
+		slotA, slotB := slotsA[i], slotsB[j]
+
+		delta := teamsByID[slotB.TeamID()].Rating - teamsByID[slotA.TeamID()].Rating
+		if delta == 0 || !allowed(constraints, withoutSlot(slotsA, i), slotB) ||
+			!allowed(constraints, withoutSlot(slotsB, j), slotA) {
+			continue
+		}
+
+		ratingTotals[a], ratingTotals[b] = ratingTotals[a]+delta, ratingTotals[b]-delta
+
+		if swapped := variance(means()); swapped < score {
+			score = swapped
+			groups[a].AssignSlot(i, slotB)
+			groups[b].AssignSlot(j, slotA)
+
+			continue
+		}
+
+		ratingTotals[a], ratingTotals[b] = ratingTotals[a]-delta, ratingTotals[b]+delta
+	}
+}
+
+func withoutSlot(slots []*entity.GroupSlot, index int) []*entity.GroupSlot {
+	result := make([]*entity.GroupSlot, 0, len(slots)-1)
+	result = append(result, slots[:index]...)
+
+	return append(result, slots[index+1:]...)
+}
+
+// leagueKeyFunc returns the key teams are protected by, nil when the protection is off.
+func leagueKeyFunc(leagueProtection string, teamsByID map[string]dto.Team) func(slot *entity.GroupSlot) string {
+	switch leagueProtection {
+	case LeagueProtectionLeague:
+		return func(slot *entity.GroupSlot) string {
+			return teamsByID[slot.TeamID()].League
+		}
+	case LeagueProtectionCountry:
+		return func(slot *entity.GroupSlot) string {
+			return leagueCountry(teamsByID[slot.TeamID()].League)
+		}
+	default:
+		return nil
+	}
+}
+
+// leagueCountry returns the country of the league, empty for the leagues without a country.
+func leagueCountry(league string) string {
+	if country, ok := leagueCountries[league]; ok {
+		return country
+	}
+
+	return league
+}
+
+// sortSlotsByKeyFrequency puts slots with the most frequent keys first, so the solver fails early.
+func sortSlotsByKeyFrequency(slots []*entity.GroupSlot, key func(slot *entity.GroupSlot) string) {
+	frequency := make(map[string]int)
+	for _, slot := range slots {
+		frequency[key(slot)]++
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return frequency[key(slots[i])] > frequency[key(slots[j])]
+	})
+}
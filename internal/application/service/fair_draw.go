@@ -0,0 +1,168 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+const (
+	serverSeedBytes   = 32
+	drawIDBytes       = 16
+	seedBytesFromHash = 8
+	// a draw changed by another request meanwhile is loaded and changed again, a few times at most.
+	drawUpdateAttempts = 3
+)
+
+var ErrCommitmentMismatch = errors.New("server seed doesn't match the commitment")
+
+// FairDraw keeps commit-reveal draws: the server seed is committed by its hash before the draw,
+// participants contribute client seeds, the seed of the tourney is derived from all of them.
+// The draws are stored, so every instance of the service sees them, and a draw is stored only over
+// the version it was changed from, so concurrent requests can't overwrite each other's changes.
+type FairDraw struct {
+	draws repository.DrawRepository
+}
+
+func NewFairDraw(draws repository.DrawRepository) *FairDraw {
+	return &FairDraw{draws: draws}
+}
+
+func (fd FairDraw) Commit() (*entity.DrawCommitment, error) {
+	serverSeed, err := randomHex(serverSeedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("generating server seed error: %w", err)
+	}
+
+	id, err := randomHex(drawIDBytes)
+	if err != nil {
+		return nil, fmt.Errorf("generating draw ID error: %w", err)
+	}
+
+	draw := entity.NewDrawCommitment(id, serverSeed, Commitment(serverSeed))
+
+	if err = fd.draws.Save(draw); err != nil {
+		return nil, fmt.Errorf("storing draw error: %w", &StorageError{Err: err})
+	}
+
+	return draw, nil
+}
+
+func (fd FairDraw) AddClientSeed(drawID, userID, seed string) (*entity.DrawCommitment, error) {
+	draw, err := fd.update(drawID, func(draw *entity.DrawCommitment) error {
+		return draw.AddClientSeed(entity.NewClientSeed(userID, seed))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adding client seed error: %w", err)
+	}
+
+	return draw, nil
+}
+
+// Claim reveals the draw before its tourney is drawn and returns the tourney seed, so two requests can't
+// draw tourneys with the same seed and client seeds can't change while the tourney is drawn.
+func (fd FairDraw) Claim(drawID string) (*entity.DrawCommitment, int64, error) {
+	draw, err := fd.update(drawID, func(draw *entity.DrawCommitment) error {
+		return draw.Reveal()
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("claiming draw error: %w", err)
+	}
+
+	return draw, DeriveSeed(draw.ServerSeed(), draw.ClientSeeds()), nil
+}
+
+// Release reopens a claimed draw whose tourney couldn't be drawn, the seed hasn't been shown to anyone then.
+func (fd FairDraw) Release(drawID string) error {
+	_, err := fd.update(drawID, func(draw *entity.DrawCommitment) error {
+		draw.Reopen()
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("releasing draw error: %w", err)
+	}
+
+	return nil
+}
+
+// update loads the draw, changes it and stores it, it starts over when another request has stored the draw meanwhile.
+func (fd FairDraw) update(drawID string, change func(draw *entity.DrawCommitment) error) (*entity.DrawCommitment, error) {
+	for attempt := 1; ; attempt++ {
+		draw, err := fd.draws.Get(drawID)
+		if err != nil {
+			return nil, drawStorageError(err)
+		}
+
+		if err = change(draw); err != nil {
+			return nil, err
+		}
+
+		err = fd.draws.Save(draw)
+		if err == nil {
+			return draw, nil
+		}
+
+		if !errors.Is(err, repository.ErrVersionConflict) || attempt == drawUpdateAttempts {
+			return nil, drawStorageError(err)
+		}
+	}
+}
+
+// drawStorageError tells the storage failures apart from the draws which don't exist or keep changing.
+func drawStorageError(err error) error {
+	if errors.Is(err, repository.ErrDrawNotFound) || errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+
+	return &StorageError{Err: err}
+}
+
+func Commitment(serverSeed string) string {
+	hash := sha256.Sum256([]byte(serverSeed))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func DeriveSeed(serverSeed string, clientSeeds []*entity.ClientSeed) int64 {
+	sortedClientSeeds := make([]*entity.ClientSeed, len(clientSeeds))
+	copy(sortedClientSeeds, clientSeeds)
+
+	sort.Slice(sortedClientSeeds, func(i, j int) bool {
+		return sortedClientSeeds[i].UserID() < sortedClientSeeds[j].UserID()
+	})
+
+	hash := sha256.New()
+	hash.Write([]byte(serverSeed))
+
+	for _, clientSeed := range sortedClientSeeds {
+		hash.Write([]byte("|" + clientSeed.UserID() + ":" + clientSeed.Seed()))
+	}
+
+	return int64(binary.BigEndian.Uint64(hash.Sum(nil)[:seedBytesFromHash]) & maxSeed)
+}
+
+// VerifySeed checks the revealed server seed against its commitment and derives the tourney seed.
+func VerifySeed(commitment, serverSeed string, clientSeeds []*entity.ClientSeed) (int64, error) {
+	if Commitment(serverSeed) != commitment {
+		return 0, ErrCommitmentMismatch
+	}
+
+	return DeriveSeed(serverSeed, clientSeeds), nil
+}
+
+func randomHex(bytesCount int) (string, error) {
+	data := make([]byte, bytesCount)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("reading random bytes error: %w", err)
+	}
+
+	return hex.EncodeToString(data), nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+// roundRobin schedules every slot of a group of the size against each other by the circle method:
+// the first slot stays in place while the others rotate. An odd group gets a bye slot, so one slot rests
+// on every matchday. The double round robin repeats the matchdays with home and away swapped.
+func roundRobin(size int, double bool) []*entity.Matchday {
+	if size < 2 {
+		return nil
+	}
+
+	positions := make([]int, size)
+	for i := range positions {
+		positions[i] = i
+	}
+
+	if size%2 == 1 {
+		positions = append(positions, entity.NoBye)
+	}
+
+	roundsCount := len(positions) - 1
+	matchdays := make([]*entity.Matchday, 0, 2*roundsCount)
+
+	for round := 0; round < roundsCount; round++ {
+		fixtures := make([]*entity.Fixture, 0, len(positions)/2)
+		byeSlotIndex := entity.NoBye
+
+		for i := 0; i < len(positions)/2; i++ {
+			home, away := positions[i], positions[len(positions)-1-i]
+
+			// the lower slot plays at home against the slots of the other index parity, so every slot
+			// gets about half of its matches at home.
+			if (home < away) != ((home+away)%2 == 1) {
+				home, away = away, home
+			}
+
+			switch {
+			case home == entity.NoBye:
+				byeSlotIndex = away
+			case away == entity.NoBye:
+				byeSlotIndex = home
+			default:
+				fixtures = append(fixtures, entity.NewFixture(home, away))
+			}
+		}
+
+		matchdays = append(matchdays, entity.NewMatchday(round+1, fixtures, byeSlotIndex))
+
+		last := positions[len(positions)-1]
+		copy(positions[2:], positions[1:len(positions)-1])
+		positions[1] = last
+	}
+
+	if !double {
+		return matchdays
+	}
+
+	for round := 0; round < roundsCount; round++ {
+		firstLeg := matchdays[round]
+
+		fixtures := make([]*entity.Fixture, len(firstLeg.Fixtures()))
+		for i, fixture := range firstLeg.Fixtures() {
+			fixtures[i] = entity.NewFixture(fixture.AwaySlotIndex(), fixture.HomeSlotIndex())
+		}
+
+		matchdays = append(matchdays, entity.NewMatchday(roundsCount+round+1, fixtures, firstLeg.ByeSlotIndex()))
+	}
+
+	return matchdays
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	GroupNamingLowercaseLetters = "lowercase_letters"
+	GroupNamingLetters          = "letters"
+	GroupNamingNumbers          = "numbers"
+
+	lettersCount = 26
+)
+
+var errGroupNames = errors.New("group names error")
+
+// groupNames names the groups by the custom names when given, otherwise by the naming: spreadsheet-style
+// letters (A…Z, AA, AB…), numbers starting with 1 or, by default, lowercase letters (a…z, aa, ab…)
+// as the groups have always been named.
+func groupNames(naming string, customNames []string, count int) []string {
+	if len(customNames) > 0 {
+		return customNames
+	}
+
+	names := make([]string, count)
+
+	for i := range names {
+		switch naming {
+		case GroupNamingNumbers:
+			names[i] = strconv.Itoa(i + 1)
+		case GroupNamingLetters:
+			names[i] = spreadsheetName(i)
+		default:
+			names[i] = strings.ToLower(spreadsheetName(i))
+		}
+	}
+
+	return names
+}
+
+func spreadsheetName(index int) string {
+	var name []rune
+
+	for index++; index > 0; index = (index - 1) / lettersCount {
+		name = append([]rune{rune('A' + (index-1)%lettersCount)}, name...)
+	}
+
+	return string(name)
+}
+
+func validateGroupNames(naming string, customNames []string, count int) error {
+	switch naming {
+	case "", GroupNamingLowercaseLetters, GroupNamingLetters, GroupNamingNumbers:
+	default:
+		return settingsError("group_naming", errGroupNames, "`%s` is unknown", naming)
+	}
+
+	if len(customNames) == 0 {
+		return nil
+	}
+
+	if naming != "" {
+		return settingsError("group_names", errGroupNames, "can't be combined with group_naming")
+	}
+
+	if len(customNames) != count {
+		return settingsError("group_names", errGroupNames, "%d names given for %d groups", len(customNames), count)
+	}
+
+	indexByName := make(map[string]int, len(customNames))
+
+	for i, name := range customNames {
+		if name == "" {
+			return settingsError(fmt.Sprintf("group_names[%d]", i), errGroupNames, "is empty")
+		}
+
+		if previous, exists := indexByName[name]; exists {
+			return settingsError(fmt.Sprintf("group_names[%d]", i), errGroupNames, "duplicates group_names[%d]", previous)
+		}
+
+		indexByName[name] = i
+	}
+
+	return nil
+}
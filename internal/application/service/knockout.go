@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+// minThirdPlaceQualifiers is the smallest bracket where both semi-finals are played.
+const minThirdPlaceQualifiers = 4
+
+var errKnockout = errors.New("knockout stage error")
+
+func (s TourneySettingsDTO) validateKnockout() error {
+	if s.qualifiersPerGroup == 0 {
+		if s.thirdPlaceMatch {
+			return settingsError("third_place_match", errKnockout, "needs knockout_qualifiers")
+		}
+
+		return nil
+	}
+
+	sizes := s.groupSizes()
+	if smallest := sizes[len(sizes)-1]; s.qualifiersPerGroup < 0 || s.qualifiersPerGroup > smallest {
+		return settingsError(
+			"knockout_qualifiers", errKnockout, "must be within 0 and the smallest group size %d, got %d", smallest, s.qualifiersPerGroup,
+		)
+	}
+
+	qualifiersCount := s.qualifiersPerGroup * s.groupsCount
+	if qualifiersCount < 2 {
+		return settingsError("knockout_qualifiers", errKnockout, "%d qualifiers can't play a knockout stage", qualifiersCount)
+	}
+
+	if s.thirdPlaceMatch && qualifiersCount < minThirdPlaceQualifiers {
+		return settingsError(
+			"third_place_match", errKnockout, "needs %d qualifiers, got %d", minThirdPlaceQualifiers, qualifiersCount,
+		)
+	}
+
+	return nil
+}
+
+// knockoutStage builds the bracket of the top qualifiersPerGroup teams of every group. Group winners are
+// seeded first, then runners-up and so on, the seeds are placed so the higher seeds meet as late as possible
+// and the byes of an incomplete bracket go to the highest seeds. Within a position the groups get the seeds
+// keeping teams of the same group apart for as many rounds as possible.
+func knockoutStage(groups []*entity.Group, qualifiersPerGroup int, thirdPlace bool) *entity.KnockoutStage {
+	qualifiersCount := qualifiersPerGroup * len(groups)
+	bracketSize := 1 << bits.Len(uint(qualifiersCount-1))
+	seedsOrder := bracketSeedsOrder(bracketSize)
+
+	bracketPositionBySeed := make([]int, bracketSize+1)
+	for position, seed := range seedsOrder {
+		bracketPositionBySeed[seed] = position
+	}
+
+	sides := make([]*entity.KnockoutSlot, bracketSize)
+	bracketPositionsByGroup := make([][]int, len(groups))
+
+	for position := 1; position <= qualifiersPerGroup; position++ {
+		assigned := make([]bool, len(groups))
+
+		for i := range groups {
+			seed := (position-1)*len(groups) + i + 1
+			bracketPosition := bracketPositionBySeed[seed]
+			groupIndex := latestMeetingGroup(bracketPosition, bracketPositionsByGroup, assigned)
+
+			assigned[groupIndex] = true
+			bracketPositionsByGroup[groupIndex] = append(bracketPositionsByGroup[groupIndex], bracketPosition)
+			sides[bracketPosition] = entity.NewQualifierSlot(groups[groupIndex].Name(), position, seed)
+		}
+	}
+
+	var (
+		rounds         []*entity.KnockoutRound
+		thirdPlaceTie  *entity.KnockoutTie
+		semiFinalsTies []*entity.KnockoutTie
+		tieNumber      int
+	)
+
+	for len(sides) > 1 {
+		// the third place match is played before the final.
+		if len(sides) == 2 && thirdPlace {
+			tieNumber++
+			thirdPlaceTie = entity.NewKnockoutTie(tieNumber,
+				entity.NewLoserSlot(semiFinalsTies[0].Number()), entity.NewLoserSlot(semiFinalsTies[1].Number()))
+		}
+
+		name := knockoutRoundName(len(sides))
+		nextSides := make([]*entity.KnockoutSlot, len(sides)/2)
+		ties := make([]*entity.KnockoutTie, 0, len(sides)/2)
+
+		for i := range nextSides {
+			home, away := sides[2*i], sides[2*i+1]
+
+			// a bye puts the side straight into the next round.
+			if away == nil {
+				nextSides[i] = home
+
+				continue
+			}
+
+			tieNumber++
+			ties = append(ties, entity.NewKnockoutTie(tieNumber, home, away))
+			nextSides[i] = entity.NewWinnerSlot(tieNumber)
+		}
+
+		rounds = append(rounds, entity.NewKnockoutRound(name, ties))
+		semiFinalsTies = ties
+		sides = nextSides
+	}
+
+	return entity.NewKnockoutStage(rounds, thirdPlaceTie)
+}
+
+// bracketSeedsOrder lists the seeds by their bracket positions, so the seed n plays the seed size+1-n
+// in the first round and the top seeds meet in the latest rounds.
+func bracketSeedsOrder(size int) []int {
+	order := []int{1}
+
+	for len(order) < size {
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+
+		order = next
+	}
+
+	return order
+}
+
+// latestMeetingGroup picks the not yet assigned group which teams would meet the team at the bracket
+// position in the latest round, the first group wins a tie.
+func latestMeetingGroup(bracketPosition int, bracketPositionsByGroup [][]int, assigned []bool) int {
+	bestGroupIndex, bestRound := -1, -1
+
+	for groupIndex, bracketPositions := range bracketPositionsByGroup {
+		if assigned[groupIndex] {
+			continue
+		}
+
+		// two positions meet in the round of the highest bit they differ in.
+		round := bits.UintSize + 1
+
+		for _, other := range bracketPositions {
+			if meeting := bits.Len(uint(bracketPosition ^ other)); meeting < round {
+				round = meeting
+			}
+		}
+
+		if round > bestRound {
+			bestGroupIndex, bestRound = groupIndex, round
+		}
+	}
+
+	return bestGroupIndex
+}
+
+func knockoutRoundName(sidesCount int) string {
+	switch sidesCount {
+	case 2:
+		return "Final"
+	case 4:
+		return "Semi-finals"
+	case 8:
+		return "Quarter-finals"
+	default:
+		return fmt.Sprintf("Round of %d", sidesCount)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+const (
+	pointsForWin  = 3
+	pointsForDraw = 1
+)
+
+var errMatchResult = errors.New("match result error")
+
+// MatchResultDTO is the score of a fixture of the named group, slots are given by their indexes in the group.
+// Fair play points are the disciplinary penalty points of a side, the fewer the better.
+type MatchResultDTO struct {
+	groupName          string
+	homeSlotIndex      int
+	awaySlotIndex      int
+	homeGoals          int
+	awayGoals          int
+	homeFairPlayPoints int
+	awayFairPlayPoints int
+}
+
+func NewMatchResultDTO(groupName string, homeSlotIndex, awaySlotIndex, homeGoals, awayGoals int) *MatchResultDTO {
+	return &MatchResultDTO{
+		groupName:     groupName,
+		homeSlotIndex: homeSlotIndex,
+		awaySlotIndex: awaySlotIndex,
+		homeGoals:     homeGoals,
+		awayGoals:     awayGoals,
+	}
+}
+
+func (r *MatchResultDTO) WithFairPlayPoints(homeFairPlayPoints, awayFairPlayPoints int) *MatchResultDTO {
+	r.homeFairPlayPoints = homeFairPlayPoints
+	r.awayFairPlayPoints = awayFairPlayPoints
+
+	return r
+}
+
+// StandingRow is the record of a group slot over the played matches. SeparatedBy names the tiebreaker
+// which ranked the slot below the previous one when they were level on the first tiebreaker.
+type StandingRow struct {
+	SlotIndex      int
+	Played         int
+	Won            int
+	Drawn          int
+	Lost           int
+	GoalsFor       int
+	GoalsAgainst   int
+	GoalDifference int
+	Points         int
+	AwayGoals      int
+	FairPlayPoints int
+	SeparatedBy    string
+	Explanation    string
+}
+
+// RecordResults adds the matches to the groups of the tourney. A result must match a scheduled fixture
+// with the same home and away slots, every fixture is played once.
+func RecordResults(tourney *entity.Tourney, results []*MatchResultDTO) error {
+	groupsByName := make(map[string]*entity.Group, len(tourney.Groups()))
+	for _, group := range tourney.Groups() {
+		groupsByName[group.Name()] = group
+	}
+
+	for i, result := range results {
+		group, ok := groupsByName[result.groupName]
+		if !ok {
+			return fmt.Errorf("result %d: group `%s` not found: %w", i, result.groupName, errMatchResult)
+		}
+
+		if err := validateResult(group, result); err != nil {
+			return fmt.Errorf("result %d: group `%s`: %w", i, result.groupName, err)
+		}
+
+		group.AddMatch(entity.NewMatch(result.homeSlotIndex, result.awaySlotIndex, result.homeGoals, result.awayGoals).
+			WithFairPlayPoints(result.homeFairPlayPoints, result.awayFairPlayPoints))
+	}
+
+	return nil
+}
+
+func validateResult(group *entity.Group, result *MatchResultDTO) error {
+	if result.homeGoals < 0 || result.awayGoals < 0 {
+		return fmt.Errorf("negative score %d:%d: %w", result.homeGoals, result.awayGoals, errMatchResult)
+	}
+
+	if result.homeFairPlayPoints < 0 || result.awayFairPlayPoints < 0 {
+		return fmt.Errorf("negative fair play points %d:%d: %w",
+			result.homeFairPlayPoints, result.awayFairPlayPoints, errMatchResult)
+	}
+
+	scheduled := false
+
+	for _, matchday := range group.Matchdays() {
+		for _, fixture := range matchday.Fixtures() {
+			if fixture.HomeSlotIndex() == result.homeSlotIndex && fixture.AwaySlotIndex() == result.awaySlotIndex {
+				scheduled = true
+			}
+		}
+	}
+
+	if !scheduled {
+		return fmt.Errorf(
+			"slot %d doesn't host slot %d: %w", result.homeSlotIndex, result.awaySlotIndex, errMatchResult,
+		)
+	}
+
+	for _, match := range group.Matches() {
+		if match.HomeSlotIndex() == result.homeSlotIndex && match.AwaySlotIndex() == result.awaySlotIndex {
+			return fmt.Errorf(
+				"slot %d vs slot %d is already played: %w", result.homeSlotIndex, result.awaySlotIndex, errMatchResult,
+			)
+		}
+	}
+
+	return nil
+}
+
+// tourneyStandings ranks every group of the tourney by its tiebreakers, the default ones when it has none.
+// The lots of a group are drawn with the tourney seed, so the standings are reproducible.
+func tourneyStandings(tourney *entity.Tourney) [][]StandingRow {
+	tiebreakers := tourney.Tiebreakers()
+	if len(tiebreakers) == 0 {
+		tiebreakers = defaultTiebreakers
+	}
+
+	standings := make([][]StandingRow, len(tourney.Groups()))
+	for i, group := range tourney.Groups() {
+		standings[i] = GroupStandings(group, tiebreakers, tourney.Seed()+int64(i))
+	}
+
+	return standings
+}
+
+// GroupStandings ranks the slots of the group applying the tiebreakers in order to the slots still level,
+// the slot order breaks the ties the tiebreakers leave.
+func GroupStandings(group *entity.Group, tiebreakers []string, lotsSeed int64) []StandingRow {
+	rows := make([]StandingRow, group.Size())
+	for i := range rows {
+		rows[i].SlotIndex = i
+	}
+
+	for _, match := range group.Matches() {
+		home, away := &rows[match.HomeSlotIndex()], &rows[match.AwaySlotIndex()]
+		home.record(match.HomeGoals(), match.AwayGoals(), match.HomeFairPlayPoints())
+		away.record(match.AwayGoals(), match.HomeGoals(), match.AwayFairPlayPoints())
+		away.AwayGoals += match.AwayGoals()
+	}
+
+	ranker := standingsRanker{
+		rows:        rows,
+		matches:     group.Matches(),
+		tiebreakers: tiebreakers,
+		lots:        rand.New(rand.NewSource(lotsSeed)).Perm(group.Size()),
+	}
+
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+
+	ranked := make([]StandingRow, 0, len(rows))
+	for _, slotIndex := range ranker.rank(order, 0) {
+		ranked = append(ranked, rows[slotIndex])
+	}
+
+	return ranked
+}
+
+func (r *StandingRow) record(goalsFor, goalsAgainst, fairPlayPoints int) {
+	r.Played++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	r.GoalDifference = r.GoalsFor - r.GoalsAgainst
+	r.FairPlayPoints += fairPlayPoints
+
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Won++
+		r.Points += pointsForWin
+	case goalsFor == goalsAgainst:
+		r.Drawn++
+		r.Points += pointsForDraw
+	default:
+		r.Lost++
+	}
+}
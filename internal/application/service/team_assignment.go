@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+
+	"github.com/twizar/common/pkg/dto"
+)
+
+const (
+	AssignmentModeRotation = "rotation"
+	AssignmentModeBalanced = "balanced"
+
+	balancingSwapsBudget = 10_000
+	balancingEpsilon     = 1e-9
+)
+
+var errAssignmentMode = errors.New("unknown assignment mode")
+
+// rotationTeamEmitter gives users their required teams first, then the best rated teams left in the pool,
+// an exhausted pool gives a zero team.
+func rotationTeamEmitter(
+	rnd *rand.Rand,
+	groupedByRatingTeams [][]dto.Team,
+	requiredTeamsGroupedByUserID map[string][]dto.Team,
+) func(userID string) dto.Team {
+	return func(userID string) dto.Team {
+		var team dto.Team
+
+		if len(requiredTeamsGroupedByUserID[userID]) > 0 {
+			shuffleTeams(rnd, requiredTeamsGroupedByUserID[userID])
+			team, requiredTeamsGroupedByUserID[userID] = popTeam(requiredTeamsGroupedByUserID[userID])
+
+			return team
+		}
+
+		for index := range groupedByRatingTeams {
+			if len(groupedByRatingTeams[index]) == 0 {
+				continue
+			}
+
+			shuffleTeams(rnd, groupedByRatingTeams[index])
+			team, groupedByRatingTeams[index] = popTeam(groupedByRatingTeams[index])
+
+			break
+		}
+
+		return team
+	}
+}
+
+func balancedTeamEmitter(assignedTeamsByUserID map[string][]dto.Team) func(userID string) dto.Team {
+	var team dto.Team
+
+	return func(userID string) dto.Team {
+		team, assignedTeamsByUserID[userID] = popTeam(assignedTeamsByUserID[userID])
+
+		return team
+	}
+}
+
+// assignBalancedTeams picks the best rated teams of the pool and shares them among users, so the users'
+// average ratings are as close as possible: greedy assignment by the rating deficit of a user is followed
+// by swaps of teams between users while the variance of the averages decreases.
+func assignBalancedTeams(
+	rnd *rand.Rand,
+	pool []dto.Team,
+	usersSettings []*UserSettingsDTO,
+	slotsCountByUserID map[string]int,
+	requiredTeamsGroupedByUserID map[string][]dto.Team,
+) map[string][]dto.Team {
+	freeSlotsByUserID := make(map[string]int, len(usersSettings))
+	ratingTotalByUserID := make(map[string]float64, len(usersSettings))
+	freeSlotsCount := 0
+
+	for _, settings := range usersSettings {
+		freeSlotsByUserID[settings.userID] = slotsCountByUserID[settings.userID] - len(requiredTeamsGroupedByUserID[settings.userID])
+		freeSlotsCount += freeSlotsByUserID[settings.userID]
+
+		for _, team := range requiredTeamsGroupedByUserID[settings.userID] {
+			ratingTotalByUserID[settings.userID] += team.Rating
+		}
+	}
+
+	pool = bestRatedTeams(rnd, pool, freeSlotsCount)
+
+	slotsCount, ratingTotal := 0, 0.0
+	for _, settings := range usersSettings {
+		slotsCount += slotsCountByUserID[settings.userID]
+		ratingTotal += ratingTotalByUserID[settings.userID]
+	}
+
+	for _, team := range pool {
+		ratingTotal += team.Rating
+	}
+
+	targetAverage := ratingTotal / float64(slotsCount)
+	assignedTeamsByUserID := make(map[string][]dto.Team, len(usersSettings))
+
+	for _, team := range pool {
+		var (
+			neediestUserID string
+			maxDeficit     float64
+		)
+
+		for _, settings := range usersSettings {
+			userID := settings.userID
+			if freeSlotsByUserID[userID] == 0 {
+				continue
+			}
+
+			deficit := (targetAverage*float64(slotsCountByUserID[userID]) - ratingTotalByUserID[userID]) / float64(freeSlotsByUserID[userID])
+			if neediestUserID == "" || deficit > maxDeficit {
+				neediestUserID, maxDeficit = userID, deficit
+			}
+		}
+
+		assignedTeamsByUserID[neediestUserID] = append(assignedTeamsByUserID[neediestUserID], team)
+		ratingTotalByUserID[neediestUserID] += team.Rating
+		freeSlotsByUserID[neediestUserID]--
+	}
+
+	balanceBySwaps(usersSettings, targetAverage, slotsCountByUserID, ratingTotalByUserID, assignedTeamsByUserID)
+
+	for userID, teams := range requiredTeamsGroupedByUserID {
+		assignedTeamsByUserID[userID] = append(assignedTeamsByUserID[userID], teams...)
+	}
+
+	return assignedTeamsByUserID
+}
+
+func balanceBySwaps(
+	usersSettings []*UserSettingsDTO,
+	targetAverage float64,
+	slotsCountByUserID map[string]int,
+	ratingTotalByUserID map[string]float64,
+	assignedTeamsByUserID map[string][]dto.Team,
+) {
+	average := func(userID string, ratingTotal float64) float64 {
+		return ratingTotal / float64(slotsCountByUserID[userID])
+	}
+
+	for swaps, improved := 0, true; improved && swaps < balancingSwapsBudget; {
+		improved = false
+
+		for a := 0; a < len(usersSettings) && !improved; a++ {
+			for b := a + 1; b < len(usersSettings) && !improved; b++ {
+				userA, userB := usersSettings[a].userID, usersSettings[b].userID
+				totalA, totalB := ratingTotalByUserID[userA], ratingTotalByUserID[userB]
+				current := sqr(average(userA, totalA)-targetAverage) + sqr(average(userB, totalB)-targetAverage)
+
+				for i, teamA := range assignedTeamsByUserID[userA] {
+					for j, teamB := range assignedTeamsByUserID[userB] {
+						delta := teamB.Rating - teamA.Rating
+						swapped := sqr(average(userA, totalA+delta)-targetAverage) + sqr(average(userB, totalB-delta)-targetAverage)
+
+						if swapped < current-balancingEpsilon {
+							assignedTeamsByUserID[userA][i], assignedTeamsByUserID[userB][j] = teamB, teamA
+							ratingTotalByUserID[userA], ratingTotalByUserID[userB] = totalA+delta, totalB-delta
+							improved = true
+							swaps++
+
+							break
+						}
+					}
+
+					if improved {
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// bestRatedTeams returns count best rated teams, teams of the same rating are picked randomly.
+func bestRatedTeams(rnd *rand.Rand, pool []dto.Team, count int) []dto.Team {
+	best := make([]dto.Team, len(pool))
+	copy(best, pool)
+
+	shuffleTeams(rnd, best)
+
+	sort.SliceStable(best, func(i, j int) bool {
+		return best[i].Rating > best[j].Rating
+	})
+
+	if count < len(best) {
+		best = best[:count]
+	}
+
+	return best
+}
+
+func sqr(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+const (
+	TiebreakerPoints                   = "points"
+	TiebreakerHeadToHeadPoints         = "head_to_head_points"
+	TiebreakerHeadToHeadGoalDifference = "head_to_head_goal_difference"
+	TiebreakerGoalDifference           = "goal_difference"
+	TiebreakerGoalsScored              = "goals_scored"
+	TiebreakerAwayGoals                = "away_goals"
+	TiebreakerFairPlay                 = "fair_play"
+	TiebreakerLots                     = "lots"
+)
+
+var (
+	errTiebreakers = errors.New("tiebreakers error")
+
+	defaultTiebreakers = []string{TiebreakerPoints, TiebreakerGoalDifference, TiebreakerGoalsScored}
+
+	tiebreakerLabels = map[string]string{
+		TiebreakerPoints:                   "points",
+		TiebreakerHeadToHeadPoints:         "head-to-head points",
+		TiebreakerHeadToHeadGoalDifference: "head-to-head goal difference",
+		TiebreakerGoalDifference:           "goal difference",
+		TiebreakerGoalsScored:              "goals scored",
+		TiebreakerAwayGoals:                "away goals",
+		TiebreakerFairPlay:                 "fair play penalty points",
+		TiebreakerLots:                     "drawn lot",
+	}
+)
+
+// validateTiebreakers accepts the known tiebreakers once each, led by the points: the first tiebreaker ranks
+// the slots and the next ones only separate the slots level on it.
+func validateTiebreakers(tiebreakers []string) error {
+	if len(tiebreakers) > 0 && tiebreakers[0] != TiebreakerPoints {
+		return settingsError("tiebreakers", errTiebreakers, "must start with `%s`", TiebreakerPoints)
+	}
+
+	seen := make(map[string]bool, len(tiebreakers))
+
+	for i, tiebreaker := range tiebreakers {
+		if _, ok := tiebreakerLabels[tiebreaker]; !ok {
+			return settingsError(fmt.Sprintf("tiebreakers[%d]", i), errTiebreakers, "`%s` is unknown", tiebreaker)
+		}
+
+		if seen[tiebreaker] {
+			return settingsError(fmt.Sprintf("tiebreakers[%d]", i), errTiebreakers, "`%s` is repeated", tiebreaker)
+		}
+
+		seen[tiebreaker] = true
+	}
+
+	return nil
+}
+
+// standingsRanker orders the slots of a group level on the previous tiebreakers by the next one.
+type standingsRanker struct {
+	rows        []StandingRow
+	matches     []*entity.Match
+	tiebreakers []string
+	lots        []int
+}
+
+func (sr standingsRanker) rank(slotIndexes []int, tiebreakerIndex int) []int {
+	if len(slotIndexes) < 2 {
+		return slotIndexes
+	}
+
+	if tiebreakerIndex == len(sr.tiebreakers) {
+		sorted := append([]int(nil), slotIndexes...)
+		sort.Ints(sorted)
+
+		for _, slotIndex := range sorted[1:] {
+			sr.rows[slotIndex].Explanation = "level on every tiebreaker, ranked by the draw order"
+		}
+
+		return sorted
+	}
+
+	tiebreaker := sr.tiebreakers[tiebreakerIndex]
+	values := sr.values(tiebreaker, slotIndexes)
+
+	sorted := append([]int(nil), slotIndexes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return values[sorted[i]] > values[sorted[j]]
+	})
+
+	ranked := make([]int, 0, len(sorted))
+
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && values[sorted[end]] == values[sorted[start]] {
+			end++
+		}
+
+		level := sr.rank(sorted[start:end], tiebreakerIndex+1)
+
+		// the first tiebreaker ranks the slots, the next ones only separate the slots level on it.
+		if start > 0 && tiebreakerIndex > 0 {
+			above, below := ranked[len(ranked)-1], level[0]
+			sr.rows[below].SeparatedBy = tiebreaker
+			sr.rows[below].Explanation = fmt.Sprintf("%s %d vs %d", tiebreakerLabels[tiebreaker],
+				tiebreakerDisplayValue(tiebreaker, values[above]), tiebreakerDisplayValue(tiebreaker, values[below]))
+		}
+
+		ranked = append(ranked, level...)
+		start = end
+	}
+
+	return ranked
+}
+
+// values rates the slots by the tiebreaker, the higher value ranks the higher. Head-to-head tiebreakers
+// count the matches between the given slots only.
+func (sr standingsRanker) values(tiebreaker string, slotIndexes []int) map[int]int {
+	values := make(map[int]int, len(slotIndexes))
+
+	switch tiebreaker {
+	case TiebreakerHeadToHeadPoints, TiebreakerHeadToHeadGoalDifference:
+		level := make(map[int]bool, len(slotIndexes))
+		for _, slotIndex := range slotIndexes {
+			level[slotIndex] = true
+		}
+
+		headToHead := make(map[int]*StandingRow, len(slotIndexes))
+		for _, slotIndex := range slotIndexes {
+			headToHead[slotIndex] = &StandingRow{}
+		}
+
+		for _, match := range sr.matches {
+			if !level[match.HomeSlotIndex()] || !level[match.AwaySlotIndex()] {
+				continue
+			}
+
+			headToHead[match.HomeSlotIndex()].record(match.HomeGoals(), match.AwayGoals(), 0)
+			headToHead[match.AwaySlotIndex()].record(match.AwayGoals(), match.HomeGoals(), 0)
+		}
+
+		for slotIndex, row := range headToHead {
+			if tiebreaker == TiebreakerHeadToHeadPoints {
+				values[slotIndex] = row.Points
+			} else {
+				values[slotIndex] = row.GoalDifference
+			}
+		}
+	default:
+		for _, slotIndex := range slotIndexes {
+			values[slotIndex] = sr.value(tiebreaker, slotIndex)
+		}
+	}
+
+	return values
+}
+
+func (sr standingsRanker) value(tiebreaker string, slotIndex int) int {
+	row := sr.rows[slotIndex]
+
+	switch tiebreaker {
+	case TiebreakerGoalDifference:
+		return row.GoalDifference
+	case TiebreakerGoalsScored:
+		return row.GoalsFor
+	case TiebreakerAwayGoals:
+		return row.AwayGoals
+	case TiebreakerFairPlay:
+		return -row.FairPlayPoints
+	case TiebreakerLots:
+		return -sr.lots[slotIndex]
+	default:
+		return row.Points
+	}
+}
+
+// tiebreakerDisplayValue turns back the values where the fewer ranks the higher.
+func tiebreakerDisplayValue(tiebreaker string, value int) int {
+	switch tiebreaker {
+	case TiebreakerFairPlay:
+		return -value
+	case TiebreakerLots:
+		return -value + 1
+	default:
+		return value
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twizar/common/pkg/client"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+const (
+	DefaultTourneysPageLimit = 20
+	MaxTourneysPageLimit     = 100
+)
+
+// ErrPageLimit tells the page limit is out of range.
+var ErrPageLimit = errors.New("page limit error")
+
+// TourneyCatalog reads, updates and deletes the stored tourneys, the draw reports are made of the current teams ratings.
+type TourneyCatalog struct {
+	teams    client.Teams
+	tourneys repository.TourneyRepository
+}
+
+func NewTourneyCatalog(teams client.Teams, tourneys repository.TourneyRepository) *TourneyCatalog {
+	return &TourneyCatalog{teams: teams, tourneys: tourneys}
+}
+
+func (tc TourneyCatalog) Tourney(id string) (*TourneyOverview, error) {
+	tourney, err := tc.tourneys.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("getting tourney error: %w", storageError(err))
+	}
+
+	overviews, err := tc.overviews([]*entity.Tourney{tourney})
+	if err != nil {
+		return nil, err
+	}
+
+	return overviews[0], nil
+}
+
+// UserTourneys pages the tourneys of the user, zero limit takes the default one.
+func (tc TourneyCatalog) UserTourneys(userID, cursor string, limit int) ([]*TourneyOverview, string, error) {
+	if limit == 0 {
+		limit = DefaultTourneysPageLimit
+	}
+
+	if limit < 1 || limit > MaxTourneysPageLimit {
+		return nil, "", fmt.Errorf("limit %d, at most %d allowed: %w", limit, MaxTourneysPageLimit, ErrPageLimit)
+	}
+
+	tourneys, nextCursor, err := tc.tourneys.ListByUser(userID, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("listing tourneys error: %w", err)
+	}
+
+	// a missing tourney of a list is the storage's failure, the list itself exists.
+	if err != nil {
+		return nil, "", fmt.Errorf("listing tourneys error: %w", &StorageError{Err: err})
+	}
+
+	overviews, err := tc.overviews(tourneys)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return overviews, nextCursor, nil
+}
+
+// RecordResults adds the results to the stored tourney and stores it again over the version it was read at,
+// so the results recorded or the deletion made meanwhile fail it with repository.ErrVersionConflict.
+func (tc TourneyCatalog) RecordResults(id string, results []*MatchResultDTO) (*TourneyOverview, error) {
+	overview, err := tc.Tourney(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = overview.RecordResults(results); err != nil {
+		return nil, fmt.Errorf("recording results error: %w", err)
+	}
+
+	if err = tc.tourneys.Save(overview.Tourney); err != nil {
+		return nil, fmt.Errorf("saving tourney `%s` error: %w", id, storageError(err))
+	}
+
+	return overview, nil
+}
+
+func (tc TourneyCatalog) DeleteTourney(id string) error {
+	if err := tc.tourneys.Delete(id); err != nil {
+		return fmt.Errorf("deleting tourney error: %w", storageError(err))
+	}
+
+	return nil
+}
+
+func (tc TourneyCatalog) overviews(tourneys []*entity.Tourney) ([]*TourneyOverview, error) {
+	overviews := make([]*TourneyOverview, len(tourneys))
+	if len(tourneys) == 0 {
+		return overviews, nil
+	}
+
+	teams, err := tc.teams.TeamsByID(tourneyTeamIDs(tourneys))
+	if err != nil {
+		return nil, fmt.Errorf("getting teams by ID error: %w", &TeamsServiceError{Err: err})
+	}
+
+	teamsByID := indexTeams(teams)
+
+	for i, tourney := range tourneys {
+		overviews[i] = newTourneyOverview(tourney, NewDrawReport(tourney, teamsByID))
+	}
+
+	return overviews, nil
+}
+
+// tourneyTeamIDs lists the teams of the tourneys' slots.
+func tourneyTeamIDs(tourneys []*entity.Tourney) (ids []string) {
+	for _, tourney := range tourneys {
+		for _, group := range tourney.Groups() {
+			for _, slot := range group.TeamSlots() {
+				ids = append(ids, slot.TeamID())
+			}
+		}
+	}
+
+	return
+}
+
+// storageError wraps the repository failures in StorageError, a missing or meanwhile changed tourney is left as it is.
+func storageError(err error) error {
+	if errors.Is(err, repository.ErrTourneyNotFound) || errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+
+	return &StorageError{Err: err}
+}
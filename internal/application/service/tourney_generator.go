@@ -3,14 +3,18 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 
 	goFunk "github.com/thoas/go-funk"
 	"github.com/twizar/common/pkg/client"
 	"github.com/twizar/common/pkg/dto"
 	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
 )
 
 type (
@@ -20,10 +24,517 @@ type (
 
 const (
 	minRatingDefault = 3
+	maxRatingDefault = 5
 	minLimitDefault  = 0
+	ratingStep       = 0.5
+
+	DrawModeClassic = "classic"
+	DrawModePots    = "pots"
+
+	RequiredTeamsPolicyReject           = "reject"
+	RequiredTeamsPolicyFirstCome        = "first_come"
+	RequiredTeamsPolicyRandomWinner     = "random_winner"
+	RequiredTeamsPolicyDuplicateAllowed = "duplicate_allowed"
+
+	// the caps keep the groups and the slots of a draw within what a teams pool can fill.
+	MaxGroupsCount   = 64
+	MaxTeamsPerGroup = 32
+	MaxTeamsCount    = 512
+
+	maxCandidates          = 100
+	maxRebalanceIterations = 100_000
+	tourneyIDBytes         = 16
+	// seeds are kept within 53 bits to survive a round trip through JSON numbers.
+	maxSeed = 1<<53 - 1
 )
 
-var errTypeAssertion = errors.New("type assertion error")
+var (
+	errTypeAssertion     = errors.New("type assertion error")
+	errSlotsDistribution = errors.New("slots distribution error")
+	errRatingRange       = errors.New("rating range error")
+	errDrawMode          = errors.New("unknown draw mode")
+	errCandidates        = errors.New("candidates count error")
+	errObjective         = errors.New("unknown objective")
+	errRebalance         = errors.New("rebalance iterations error")
+	errPoolExhausted     = errors.New("teams pool exhausted")
+	errRequiredTeams     = errors.New("required teams error")
+	errUnknownTeams      = errors.New("unknown required teams")
+	errGroupSizes        = errors.New("group sizes error")
+)
+
+// UnknownTeamsError lists the required teams the teams service doesn't know.
+type UnknownTeamsError struct {
+	TeamIDs []string
+}
+
+func (e *UnknownTeamsError) Error() string {
+	return fmt.Sprintf("%s: %s", errUnknownTeams, strings.Join(e.TeamIDs, ", "))
+}
+
+func (e *UnknownTeamsError) Unwrap() error {
+	return errUnknownTeams
+}
+
+// TeamsServiceError wraps failures of the teams service the generator depends on.
+type TeamsServiceError struct {
+	Err error
+}
+
+func (e *TeamsServiceError) Error() string {
+	return fmt.Sprintf("teams service error: %v", e.Err)
+}
+
+func (e *TeamsServiceError) Unwrap() error {
+	return e.Err
+}
+
+// SettingsError tells the setting a draw can't take, the settings are named after the fields
+// of the tourney request, so the reason can be shown next to the field.
+type SettingsError struct {
+	Setting string
+	Reason  string
+	Err     error
+}
+
+func settingsError(setting string, err error, reason string, args ...interface{}) *SettingsError {
+	return &SettingsError{Setting: setting, Reason: fmt.Sprintf(reason, args...), Err: err}
+}
+
+func (e *SettingsError) Error() string {
+	return fmt.Sprintf("%s: %s %s", e.Err, e.Setting, e.Reason)
+}
+
+func (e *SettingsError) Unwrap() error {
+	return e.Err
+}
+
+// StorageError wraps failures of the tourneys storage.
+type StorageError struct {
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("tourneys storage error: %v", e.Err)
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// PoolExhaustedError tells the teams pool is smaller than the draw needs.
+type PoolExhaustedError struct {
+	Needed    int
+	Available int
+	MinRating float64
+	MaxRating float64
+}
+
+func (e *PoolExhaustedError) Error() string {
+	return fmt.Sprintf(
+		"%s: rating range [%v, %v] yields %d teams, %d needed", errPoolExhausted, e.MinRating, e.MaxRating, e.Available, e.Needed,
+	)
+}
+
+func (e *PoolExhaustedError) Unwrap() error {
+	return errPoolExhausted
+}
+
+type TourneySettingsDTO struct {
+	groupsCount      int
+	teamsPerGroup    int
+	leagues          []string
+	minRating        float64
+	maxRating        float64
+	seed             *int64
+	drawMode         string
+	leagueProtection string
+	// userSpread keeps slots of a user in different groups, maxUserSlotsPerGroup caps them explicitly.
+	userSpread           bool
+	maxUserSlotsPerGroup int
+	assignmentMode       string
+	candidates           int
+	top                  int
+	objective            string
+	rebalanceIterations  int
+	// constraints selects the draw constraints by name, nil derives them from the options above.
+	constraints []string
+	rivals      [][]string
+	// allowLowerRatings lowers the min rating tier by tier when the pool is short.
+	allowLowerRatings bool
+	// requiredTeamsPolicy settles teams required by several users.
+	requiredTeamsPolicy string
+	// substituteUnknownTeams replaces unknown required teams with similar rated ones instead of failing.
+	substituteUnknownTeams bool
+	groupNaming            string
+	groupNames             []string
+	// teamsCount sizes the groups as evenly as possible instead of teamsPerGroup.
+	teamsCount       int
+	doubleRoundRobin bool
+	// qualifiersPerGroup is the number of teams of every group going to the knockout stage, 0 skips it.
+	qualifiersPerGroup int
+	thirdPlaceMatch    bool
+	// tiebreakers rank the group standings in order.
+	tiebreakers []string
+}
+
+func NewTourneySettingsDTO(groupsCount, teamsPerGroup int, leagues []string) *TourneySettingsDTO {
+	return &TourneySettingsDTO{
+		groupsCount:      groupsCount,
+		teamsPerGroup:    teamsPerGroup,
+		leagues:          leagues,
+		minRating:        minRatingDefault,
+		maxRating:        maxRatingDefault,
+		drawMode:         DrawModeClassic,
+		leagueProtection: LeagueProtectionNone,
+		userSpread:       true,
+		assignmentMode:   AssignmentModeRotation,
+		candidates:       1,
+		top:              1,
+		objective:        ObjectiveGroupRatingVariance,
+
+		requiredTeamsPolicy: RequiredTeamsPolicyReject,
+		tiebreakers:         defaultTiebreakers,
+	}
+}
+
+// WithRatingRange limits the teams pool to the given ratings, zero values keep the defaults.
+// The default min rating goes down to a max rating below it, so "no teams above 2.5" needs no min rating.
+func (s *TourneySettingsDTO) WithRatingRange(minRating, maxRating float64) *TourneySettingsDTO {
+	if maxRating != 0 {
+		s.maxRating = maxRating
+	}
+
+	switch {
+	case minRating != 0:
+		s.minRating = minRating
+	case s.maxRating < s.minRating:
+		s.minRating = s.maxRating
+	}
+
+	return s
+}
+
+// WithLowerRatingsFallback lets the pool take teams rated below the min rating when the range is short of teams.
+func (s *TourneySettingsDTO) WithLowerRatingsFallback(allowLowerRatings bool) *TourneySettingsDTO {
+	s.allowLowerRatings = allowLowerRatings
+
+	return s
+}
+
+// WithRequiredTeamsPolicy selects who gets a team required by several users, empty policy rejects such teams.
+func (s *TourneySettingsDTO) WithRequiredTeamsPolicy(requiredTeamsPolicy string) *TourneySettingsDTO {
+	if requiredTeamsPolicy != "" {
+		s.requiredTeamsPolicy = requiredTeamsPolicy
+	}
+
+	return s
+}
+
+// WithUnknownTeamsSubstitution gives users a similar rated team instead of an unknown required one,
+// without it unknown required teams fail the generation.
+func (s *TourneySettingsDTO) WithUnknownTeamsSubstitution(substituteUnknownTeams bool) *TourneySettingsDTO {
+	s.substituteUnknownTeams = substituteUnknownTeams
+
+	return s
+}
+
+// WithTeamsCount sizes the groups by the total teams count as evenly as possible, bigger groups go first,
+// zero count keeps teams per group.
+func (s *TourneySettingsDTO) WithTeamsCount(teamsCount int) *TourneySettingsDTO {
+	s.teamsCount = teamsCount
+
+	return s
+}
+
+// groupSizes returns the number of slots of every group.
+func (s TourneySettingsDTO) groupSizes() []int {
+	sizes := make([]int, s.groupsCount)
+
+	for i := range sizes {
+		switch {
+		case s.teamsCount == 0:
+			sizes[i] = s.teamsPerGroup
+		case i < s.teamsCount%s.groupsCount:
+			sizes[i] = s.teamsCount/s.groupsCount + 1
+		default:
+			sizes[i] = s.teamsCount / s.groupsCount
+		}
+	}
+
+	return sizes
+}
+
+func (s TourneySettingsDTO) slotsCount() int {
+	if s.teamsCount != 0 {
+		return s.teamsCount
+	}
+
+	return s.groupsCount * s.teamsPerGroup
+}
+
+// validateGroupSizes checks the caps before the slots are counted, so the count can't overflow.
+func (s TourneySettingsDTO) validateGroupSizes() error {
+	if s.groupsCount < 1 || s.groupsCount > MaxGroupsCount {
+		return settingsError("groups_count", errGroupSizes, "must be within 1 and %d, got %d", MaxGroupsCount, s.groupsCount)
+	}
+
+	if s.teamsCount != 0 {
+		if s.teamsCount < s.groupsCount || s.teamsCount > MaxTeamsCount {
+			return settingsError(
+				"teams_count", errGroupSizes, "must be within %d and %d for %d groups, got %d",
+				s.groupsCount, MaxTeamsCount, s.groupsCount, s.teamsCount,
+			)
+		}
+
+		return nil
+	}
+
+	if s.teamsPerGroup < 1 || s.teamsPerGroup > MaxTeamsPerGroup {
+		return settingsError("teams_per_group", errGroupSizes, "must be within 1 and %d, got %d", MaxTeamsPerGroup, s.teamsPerGroup)
+	}
+
+	if s.groupsCount*s.teamsPerGroup > MaxTeamsCount {
+		return settingsError(
+			"teams_per_group", errGroupSizes, "%d groups of %d teams exceed %d teams", s.groupsCount, s.teamsPerGroup, MaxTeamsCount,
+		)
+	}
+
+	return nil
+}
+
+// WithDoubleRoundRobin schedules every pair of a group to meet twice, at home and away.
+func (s *TourneySettingsDTO) WithDoubleRoundRobin(doubleRoundRobin bool) *TourneySettingsDTO {
+	s.doubleRoundRobin = doubleRoundRobin
+
+	return s
+}
+
+// WithKnockout adds the knockout stage of the top qualifiersPerGroup teams of every group.
+func (s *TourneySettingsDTO) WithKnockout(qualifiersPerGroup int, thirdPlaceMatch bool) *TourneySettingsDTO {
+	s.qualifiersPerGroup = qualifiersPerGroup
+	s.thirdPlaceMatch = thirdPlaceMatch
+
+	return s
+}
+
+// WithTiebreakers ranks the group standings by the tiebreakers in order, empty keeps the default ones.
+func (s *TourneySettingsDTO) WithTiebreakers(tiebreakers []string) *TourneySettingsDTO {
+	if len(tiebreakers) > 0 {
+		s.tiebreakers = tiebreakers
+	}
+
+	return s
+}
+
+// WithGroupNames names the groups by the naming or by the custom names, one per group, only one of them
+// can be given, empty values keep the lowercase letters.
+func (s *TourneySettingsDTO) WithGroupNames(naming string, names []string) *TourneySettingsDTO {
+	s.groupNaming = naming
+	s.groupNames = names
+
+	return s
+}
+
+// WithSeed makes the draw reproducible, nil seed lets the generator pick one.
+func (s *TourneySettingsDTO) WithSeed(seed *int64) *TourneySettingsDTO {
+	s.seed = seed
+
+	return s
+}
+
+// WithDrawMode selects how slots are distributed among groups, empty mode keeps the classic draw.
+func (s *TourneySettingsDTO) WithDrawMode(drawMode string) *TourneySettingsDTO {
+	if drawMode != "" {
+		s.drawMode = drawMode
+	}
+
+	return s
+}
+
+// WithLeagueProtection keeps teams of the same league or country in different groups.
+func (s *TourneySettingsDTO) WithLeagueProtection(leagueProtection string) *TourneySettingsDTO {
+	if leagueProtection != "" {
+		s.leagueProtection = leagueProtection
+	}
+
+	return s
+}
+
+// WithUserSpread caps slots of a user per group, zero cap spreads the slots as evenly as possible.
+func (s *TourneySettingsDTO) WithUserSpread(userSpread bool, maxUserSlotsPerGroup int) *TourneySettingsDTO {
+	s.userSpread = userSpread
+	s.maxUserSlotsPerGroup = maxUserSlotsPerGroup
+
+	return s
+}
+
+// WithAssignmentMode selects how teams of the pool are shared among users, empty mode keeps the rotation.
+func (s *TourneySettingsDTO) WithAssignmentMode(assignmentMode string) *TourneySettingsDTO {
+	if assignmentMode != "" {
+		s.assignmentMode = assignmentMode
+	}
+
+	return s
+}
+
+// WithCandidates draws the given number of candidates and keeps top of them by the objective,
+// zero values keep a single draw.
+func (s *TourneySettingsDTO) WithCandidates(candidates, top int, objective string) *TourneySettingsDTO {
+	if candidates != 0 {
+		s.candidates = candidates
+	}
+
+	if top != 0 {
+		s.top = top
+	}
+
+	if objective != "" {
+		s.objective = objective
+	}
+
+	return s
+}
+
+// WithRebalance swaps slots between groups after the draw to even out the groups' average ratings,
+// the iterations bound the time spent, zero iterations turn it off.
+func (s *TourneySettingsDTO) WithRebalance(iterations int) *TourneySettingsDTO {
+	s.rebalanceIterations = iterations
+
+	return s
+}
+
+// WithConstraints selects the draw constraints by name replacing the ones implied by the draw mode,
+// league protection and user spread, empty names keep the implied ones. Every rivals entry lists teams
+// which have to be drawn into different groups.
+func (s *TourneySettingsDTO) WithConstraints(names []string, rivals [][]string) *TourneySettingsDTO {
+	if len(names) != 0 {
+		s.constraints = names
+	}
+
+	s.rivals = rivals
+
+	return s
+}
+
+// constraintNames resolves the names of the constraints the draw has to satisfy.
+func (s TourneySettingsDTO) constraintNames() map[string]bool {
+	names := make(map[string]bool)
+
+	if s.constraints != nil {
+		for _, name := range s.constraints {
+			names[name] = true
+		}
+
+		return names
+	}
+
+	names[ConstraintPots] = s.drawMode == DrawModePots
+	names[ConstraintLeagueProtection] = s.leagueProtection != LeagueProtectionNone
+	names[ConstraintUserSpread] = s.userSpread
+	names[ConstraintRivalSeparation] = len(s.rivals) > 0
+
+	return names
+}
+
+func (s TourneySettingsDTO) validateDrawOptions() error {
+	if s.drawMode != DrawModeClassic && s.drawMode != DrawModePots {
+		return settingsError("draw_mode", errDrawMode, "`%s` is unknown", s.drawMode)
+	}
+
+	switch s.leagueProtection {
+	case LeagueProtectionNone, LeagueProtectionLeague, LeagueProtectionCountry:
+	default:
+		return settingsError("league_protection", errLeagueProtection, "`%s` is unknown", s.leagueProtection)
+	}
+
+	if s.assignmentMode != AssignmentModeRotation && s.assignmentMode != AssignmentModeBalanced {
+		return settingsError("assignment_mode", errAssignmentMode, "`%s` is unknown", s.assignmentMode)
+	}
+
+	if err := s.validateCandidates(); err != nil {
+		return err
+	}
+
+	if s.rebalanceIterations < 0 || s.rebalanceIterations > maxRebalanceIterations {
+		return settingsError(
+			"rebalance_iterations", errRebalance, "must be within 0 and %d, got %d", maxRebalanceIterations, s.rebalanceIterations,
+		)
+	}
+
+	if s.maxUserSlotsPerGroup < 0 {
+		return settingsError("max_user_slots_per_group", errUserSpread, "must not be negative, got %d", s.maxUserSlotsPerGroup)
+	}
+
+	if s.maxUserSlotsPerGroup > 0 && !s.userSpread {
+		return settingsError("max_user_slots_per_group", errUserSpread, "can't be set when user_spread is disabled")
+	}
+
+	switch s.requiredTeamsPolicy {
+	case RequiredTeamsPolicyReject, RequiredTeamsPolicyFirstCome,
+		RequiredTeamsPolicyRandomWinner, RequiredTeamsPolicyDuplicateAllowed:
+	default:
+		return settingsError("required_teams_policy", errRequiredTeams, "`%s` is unknown", s.requiredTeamsPolicy)
+	}
+
+	if err := validateGroupNames(s.groupNaming, s.groupNames, s.groupsCount); err != nil {
+		return err
+	}
+
+	if err := validateConstraintNames(s.constraints); err != nil {
+		return err
+	}
+
+	if err := validateRivals(s.rivals); err != nil {
+		return err
+	}
+
+	if err := validateTiebreakers(s.tiebreakers); err != nil {
+		return err
+	}
+
+	return s.validateKnockout()
+}
+
+func (s TourneySettingsDTO) validateCandidates() error {
+	if s.candidates < 1 || s.candidates > maxCandidates {
+		return settingsError("candidates", errCandidates, "must be within 1 and %d, got %d", maxCandidates, s.candidates)
+	}
+
+	if s.top < 1 || s.top > s.candidates {
+		return settingsError("top", errCandidates, "must be within 1 and the %d candidates, got %d", s.candidates, s.top)
+	}
+
+	switch s.objective {
+	case ObjectiveGroupRatingVariance, ObjectiveUserFairness, ObjectiveLeagueCollisions:
+	default:
+		return settingsError("objective", errObjective, "`%s` is unknown", s.objective)
+	}
+
+	return nil
+}
+
+func (s TourneySettingsDTO) validateRatingRange() error {
+	for _, rating := range []struct {
+		setting string
+		value   float64
+	}{
+		{setting: "min_rating", value: s.minRating},
+		{setting: "max_rating", value: s.maxRating},
+	} {
+		if rating.value < ratingStep || rating.value > maxRatingDefault || math.Mod(rating.value, ratingStep) != 0 {
+			return settingsError(
+				rating.setting, errRatingRange, "must be a half-star rating between %v and %v, got %v",
+				ratingStep, maxRatingDefault, rating.value,
+			)
+		}
+	}
+
+	if s.minRating > s.maxRating {
+		return settingsError("min_rating", errRatingRange, "%v is greater than max_rating %v", s.minRating, s.maxRating)
+	}
+
+	return nil
+}
 
 type UserSettingsDTO struct {
 	userID          string
@@ -35,151 +546,632 @@ func NewUserSettingsDTO(userID string, teamsCount int, requiredTeamIDs []string)
 	return &UserSettingsDTO{userID: userID, teamsCount: teamsCount, requiredTeamIDs: requiredTeamIDs}
 }
 
+type SeedSource interface {
+	Seed() int64
+}
+
+type TimeSeedSource struct{}
+
+func (TimeSeedSource) Seed() int64 {
+	return time.Now().UnixNano() & maxSeed
+}
+
 type TourneyGenerator struct {
-	teams client.Teams
+	teams      client.Teams
+	seedSource SeedSource
+	tourneys   repository.TourneyRepository
+}
+
+func NewTourneyGenerator(teams client.Teams, seedSource SeedSource, tourneys repository.TourneyRepository) *TourneyGenerator {
+	return &TourneyGenerator{teams: teams, seedSource: seedSource, tourneys: tourneys}
+}
+
+// drawInput keeps everything a draw needs, so several candidates can be drawn from the same data.
+type drawInput struct {
+	settings                     *TourneySettingsDTO
+	usersSettings                []*UserSettingsDTO
+	slotsCountByUserID           map[string]int
+	pool                         []dto.Team
+	teamsPool                    *entity.TeamsPool
+	requiredTeamsGroupedByUserID map[string][]dto.Team
+	teamsByID                    map[string]dto.Team
+	contestedTeams               []*entity.ContestedTeam
+	substitutions                []*entity.TeamSubstitution
+}
+
+type scoredTourney struct {
+	tourney *entity.Tourney
+	report  *DrawReport
+	score   float64
 }
 
-func NewTourneyGenerator(teams client.Teams) *TourneyGenerator {
-	return &TourneyGenerator{teams: teams}
+// Generate draws the tourneys and gives them new IDs, Store keeps them.
+func (tg TourneyGenerator) Generate(settings *TourneySettingsDTO, usersSettings []*UserSettingsDTO) ([]*TourneyOverview, error) {
+	overviews, err := tg.Draw(settings, usersSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().UTC()
+
+	for _, overview := range overviews {
+		id, idErr := randomHex(tourneyIDBytes)
+		if idErr != nil {
+			return nil, fmt.Errorf("generating tourney ID error: %w", idErr)
+		}
+
+		overview.Tourney.AssignID(id, createdAt)
+	}
+
+	return overviews, nil
+}
+
+// Store saves the generated tourneys, the ones already saved are deleted when another one fails.
+func (tg TourneyGenerator) Store(overviews []*TourneyOverview) error {
+	for i, overview := range overviews {
+		if err := tg.tourneys.Save(overview.Tourney); err != nil {
+			for _, saved := range overviews[:i] {
+				if deleteErr := tg.tourneys.Delete(saved.Tourney.ID()); deleteErr != nil {
+					log.Printf("deleting tourney `%s` error: %v\n", saved.Tourney.ID(), deleteErr)
+				}
+			}
+
+			return fmt.Errorf("saving tourney `%s` error: %w", overview.Tourney.ID(), &StorageError{Err: err})
+		}
+	}
+
+	return nil
+}
+
+// Draw draws the candidates and returns the best ones by the objective, the best tourney goes first.
+// The tourneys aren't stored, so a seeded draw can be repeated to verify it.
+func (tg TourneyGenerator) Draw(settings *TourneySettingsDTO, usersSettings []*UserSettingsDTO) ([]*TourneyOverview, error) {
+	seed := tg.seedSource.Seed()
+	if settings.seed != nil {
+		seed = *settings.seed
+	}
+
+	input, err := tg.prepare(settings, usersSettings, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]scoredTourney, 0, settings.candidates)
+
+	for _, candidateSeed := range candidateSeeds(seed, settings.candidates) {
+		tourney, drawErr := draw(input, candidateSeed)
+		if drawErr != nil {
+			err = drawErr
+
+			continue
+		}
+
+		report := NewDrawReport(tourney, input.teamsByID)
+		candidates = append(candidates, scoredTourney{tourney: tourney, report: report, score: report.Score(settings.objective)})
+	}
+
+	if len(candidates) == 0 {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	overviews := make([]*TourneyOverview, 0, settings.top)
+	for i := 0; i < len(candidates) && i < settings.top; i++ {
+		overviews = append(overviews, newTourneyOverview(candidates[i].tourney, candidates[i].report))
+	}
+
+	return overviews, nil
 }
 
-func (tg TourneyGenerator) Generate(
-	groupsCount,
-	teamsPerGroup int,
-	leagues []string,
+func (tg TourneyGenerator) prepare(
+	settings *TourneySettingsDTO,
 	usersSettings []*UserSettingsDTO,
-) (*entity.Tourney, error) {
-	teams, err := tg.teams.SearchTeams(minRatingDefault, leagues, "rating", minLimitDefault)
+	seed int64,
+) (*drawInput, error) {
+	if err := settings.validateGroupSizes(); err != nil {
+		return nil, fmt.Errorf("validating group sizes error: %w", err)
+	}
+
+	slotsCountByUserID, err := distributeSlots(usersSettings, settings.slotsCount())
 	if err != nil {
-		return nil, fmt.Errorf("searching teams error: %w", err)
+		return nil, fmt.Errorf("distributing slots error: %w", err)
 	}
 
-	requiredTeamIDs := requiredTeamIDsFromUserSettings(usersSettings)
+	if err = settings.validateRatingRange(); err != nil {
+		return nil, fmt.Errorf("validating rating range error: %w", err)
+	}
+
+	if err = settings.validateDrawOptions(); err != nil {
+		return nil, fmt.Errorf("validating draw options error: %w", err)
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
 
-	requiredTeams, err := tg.teams.TeamsByID(requiredTeamIDs)
+	usersSettings, contestedTeams, err := resolveRequiredTeams(rnd, usersSettings, settings.requiredTeamsPolicy)
 	if err != nil {
-		return nil, fmt.Errorf("getting teams by ID error: %w", err)
+		return nil, fmt.Errorf("resolving required teams error: %w", err)
 	}
 
-	var ok bool
+	requiredTeamIDs := requiredTeamIDsFromUserSettings(usersSettings)
+
+	var requiredTeams []dto.Team
+
+	if len(requiredTeamIDs) > 0 {
+		if requiredTeams, err = tg.teams.TeamsByID(requiredTeamIDs); err != nil {
+			return nil, fmt.Errorf("getting teams by ID error: %w", &TeamsServiceError{Err: err})
+		}
+	}
+
+	unknownTeamIDs := missingTeamIDs(requiredTeamIDs, requiredTeams)
+	if len(unknownTeamIDs) > 0 && !settings.substituteUnknownTeams {
+		return nil, &UnknownTeamsError{TeamIDs: unknownTeamIDs}
+	}
 
 	requiredTeamsGroupedByUserID, err := groupTeams(usersSettings, requiredTeams)
 	if err != nil {
 		return nil, fmt.Errorf("group teams error: %w", err)
 	}
 
-	if teams, ok = goFunk.Filter(teams, func(team dto.Team) bool {
-		return !goFunk.ContainsString(requiredTeamIDs, team.ID)
-	}).([]dto.Team); !ok {
-		return nil, fmt.Errorf("DTO teams slice assertion error: %w", errTypeAssertion)
+	assignedRequiredTeamsCount := 0
+	for _, userRequiredTeams := range requiredTeamsGroupedByUserID {
+		assignedRequiredTeamsCount += len(userRequiredTeams)
 	}
 
-	groupedByRatingTeams := groupTeamsByRating(teams)
+	teams, minRating, err := tg.searchPool(settings, requiredTeamIDs, settings.slotsCount()-assignedRequiredTeamsCount)
+	if err != nil {
+		return nil, fmt.Errorf("searching teams pool error: %w", err)
+	}
 
-	groups := generateShuffledGroups(groupsCount, teamsPerGroup)
+	pool := entity.NewTeamsPool(minRating, settings.maxRating, len(teams))
 
-	var team dto.Team
+	var substitutes []dto.Team
 
-	emitTeamForUser := func(userID string) dto.Team {
-		if len(requiredTeamsGroupedByUserID[userID]) > 0 {
-			shuffleTeams(requiredTeamsGroupedByUserID[userID])
-			team, requiredTeamsGroupedByUserID[userID] = popTeam(requiredTeamsGroupedByUserID[userID])
+	substitutions := make([]*entity.TeamSubstitution, 0, len(unknownTeamIDs))
 
-			return team
+	// the substitutes come out of the pool, so it has to hold one for every unknown team a user requires.
+	if substitutesCount := unknownRequiredTeamsCount(usersSettings, unknownTeamIDs); substitutesCount > len(teams) {
+		return nil, &PoolExhaustedError{
+			Needed:    substitutesCount,
+			Available: len(teams),
+			MinRating: settings.minRating,
+			MaxRating: settings.maxRating,
 		}
+	}
 
-		for index := range groupedByRatingTeams {
-			if len(groupedByRatingTeams[index]) == 0 {
+	for _, userSettings := range usersSettings {
+		for _, teamID := range userSettings.requiredTeamIDs {
+			if !goFunk.ContainsString(unknownTeamIDs, teamID) {
 				continue
 			}
 
-			shuffleTeams(groupedByRatingTeams[index])
-			team, groupedByRatingTeams[index] = popTeam(groupedByRatingTeams[index])
+			var substitute dto.Team
+
+			substitute, teams = popSimilarTeam(rnd, teams, requiredTeamsGroupedByUserID[userSettings.userID], settings.maxRating)
+			requiredTeamsGroupedByUserID[userSettings.userID] = append(requiredTeamsGroupedByUserID[userSettings.userID], substitute)
+			substitutes = append(substitutes, substitute)
+			substitutions = append(substitutions, entity.NewTeamSubstitution(userSettings.userID, teamID, substitute.ID))
+		}
+	}
+
+	return &drawInput{
+		settings:                     settings,
+		usersSettings:                usersSettings,
+		slotsCountByUserID:           slotsCountByUserID,
+		pool:                         teams,
+		teamsPool:                    pool,
+		requiredTeamsGroupedByUserID: requiredTeamsGroupedByUserID,
+		teamsByID:                    indexTeams(teams, requiredTeams, substitutes),
+		contestedTeams:               contestedTeams,
+		substitutions:                substitutions,
+	}, nil
+}
+
+// searchPool finds the teams to draw from and the min rating it ended at, the min rating goes
+// a tier lower at a time while the pool is short and the settings allow it.
+func (tg TourneyGenerator) searchPool(
+	settings *TourneySettingsDTO,
+	excludedTeamIDs []string,
+	neededTeamsCount int,
+) ([]dto.Team, float64, error) {
+	minRating := settings.minRating
+
+	for {
+		teams, err := tg.teams.SearchTeams(minRating, settings.leagues, "rating", minLimitDefault)
+		if err != nil {
+			return nil, 0, fmt.Errorf("searching teams error: %w", &TeamsServiceError{Err: err})
+		}
 
-			break
+		pool, ok := goFunk.Filter(teams, func(team dto.Team) bool {
+			return team.Rating >= minRating && team.Rating <= settings.maxRating &&
+				!goFunk.ContainsString(excludedTeamIDs, team.ID)
+		}).([]dto.Team)
+		if !ok {
+			return nil, 0, fmt.Errorf("DTO teams slice assertion error: %w", errTypeAssertion)
 		}
 
-		return team
+		if len(pool) >= neededTeamsCount {
+			return pool, minRating, nil
+		}
+
+		if !settings.allowLowerRatings || minRating-ratingStep < ratingStep {
+			return nil, 0, &PoolExhaustedError{
+				Needed:    neededTeamsCount,
+				Available: len(pool),
+				MinRating: minRating,
+				MaxRating: settings.maxRating,
+			}
+		}
+
+		minRating -= ratingStep
+	}
+}
+
+func draw(input *drawInput, seed int64) (*entity.Tourney, error) {
+	settings := input.settings
+	rnd := rand.New(rand.NewSource(seed))
+
+	names := groupNames(settings.groupNaming, settings.groupNames, settings.groupsCount)
+	groups := generateShuffledGroups(rnd, names, settings.groupSizes())
+
+	requiredTeamsGroupedByUserID := make(map[string][]dto.Team, len(input.requiredTeamsGroupedByUserID))
+	for userID, teams := range input.requiredTeamsGroupedByUserID {
+		requiredTeamsGroupedByUserID[userID] = append([]dto.Team(nil), teams...)
 	}
 
-	fillGroups(groups, groupsCount, teamsPerGroup, usersSettings, emitTeamForUser)
+	var emitTeamForUser func(userID string) dto.Team
 
-	groups = normalizeGroups(groups)
+	switch settings.assignmentMode {
+	case AssignmentModeBalanced:
+		emitTeamForUser = balancedTeamEmitter(
+			assignBalancedTeams(rnd, input.pool, input.usersSettings, input.slotsCountByUserID, requiredTeamsGroupedByUserID),
+		)
+	default:
+		emitTeamForUser = rotationTeamEmitter(rnd, groupTeamsByRating(input.pool), requiredTeamsGroupedByUserID)
+	}
+
+	slotsBucket, err := emitSlots(
+		settings.slotsCount(), input.usersSettings, input.slotsCountByUserID, emitTeamForUser,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("emitting slots error: %w", err)
+	}
+
+	constraints, err := drawGroups(rnd, settings, groups, input.usersSettings, input.slotsCountByUserID, slotsBucket, input.teamsByID)
+	if err != nil {
+		return nil, fmt.Errorf("drawing groups error: %w", err)
+	}
 
-	return entity.NewTourney("", groupsCount, teamsPerGroup, groups), nil
+	rebalanceGroups(rnd, groups, constraints, input.teamsByID, settings.rebalanceIterations)
+
+	groups = normalizeGroups(rnd, groups, names)
+
+	for _, group := range groups {
+		group.AssignMatchdays(roundRobin(group.Size(), settings.doubleRoundRobin))
+	}
+
+	tourney := entity.NewTourney("", settings.groupsCount, settings.teamsPerGroup, seed, groups)
+	for _, contestedTeam := range input.contestedTeams {
+		tourney.AddContestedTeam(contestedTeam)
+	}
+
+	for _, substitution := range input.substitutions {
+		tourney.AddSubstitution(substitution)
+	}
+
+	tourney.AssignTiebreakers(settings.tiebreakers)
+	tourney.AssignPool(input.teamsPool)
+
+	if settings.qualifiersPerGroup > 0 {
+		tourney.AssignKnockout(knockoutStage(groups, settings.qualifiersPerGroup, settings.thirdPlaceMatch))
+	}
+
+	return tourney, nil
 }
 
-func fillGroups(
-	groups []*entity.Group,
-	groupsCount,
-	teamsPerGroup int,
+// candidateSeeds starts with the given seed, so a single candidate is the plain seeded draw
+// and every candidate can be reproduced by its own seed.
+func candidateSeeds(seed int64, count int) []int64 {
+	seeds := make([]int64, count)
+	rnd := rand.New(rand.NewSource(seed))
+
+	for i := range seeds {
+		if i == 0 {
+			seeds[i] = seed
+
+			continue
+		}
+
+		seeds[i] = rnd.Int63() & maxSeed
+	}
+
+	return seeds
+}
+
+func emitSlots(
+	slotsCount int,
 	usersSettings []*UserSettingsDTO,
+	slotsCountByUserID map[string]int,
 	emitTeamForUser func(userID string) dto.Team,
-) {
+) ([]*entity.GroupSlot, error) {
+	remainingSlotsByUserID := make(map[string]int, len(slotsCountByUserID))
+	for userID, slotsCount := range slotsCountByUserID {
+		remainingSlotsByUserID[userID] = slotsCount
+	}
+
 	currentPointer := 0
 	emitUserID := func() string {
-		if currentPointer == len(usersSettings) {
-			currentPointer = 0
-		}
+		for {
+			if currentPointer == len(usersSettings) {
+				currentPointer = 0
+			}
 
-		userID := usersSettings[currentPointer].userID
-		currentPointer++
+			userID := usersSettings[currentPointer].userID
+			currentPointer++
 
-		return userID
-	}
+			if remainingSlotsByUserID[userID] > 0 {
+				remainingSlotsByUserID[userID]--
 
-	isGroupFullFilled := func(group *entity.Group) bool {
-		for i := 0; i < teamsPerGroup; i++ {
-			if group.TeamSlots()[i] == nil {
-				return false
+				return userID
 			}
 		}
-
-		return true
 	}
 
-	teamsCount := groupsCount * teamsPerGroup
-
-	slotsBucket := make([]*entity.GroupSlot, teamsCount)
+	slotsBucket := make([]*entity.GroupSlot, slotsCount)
 
-	for i := 0; i < teamsCount; i++ {
+	for i := 0; i < slotsCount; i++ {
 		userID := emitUserID()
 		teamID := emitTeamForUser(userID).ID
+
+		if teamID == "" {
+			return nil, fmt.Errorf("no team left for user `%s`: %w", userID, errPoolExhausted)
+		}
+
 		slotsBucket[i] = entity.NewGroupSlot(userID, teamID)
 	}
 
+	return slotsBucket, nil
+}
+
+func fillGroups(rnd *rand.Rand, groups []*entity.Group, usersSettings []*UserSettingsDTO, slotsBucket []*entity.GroupSlot) {
 	currentEmittedUserIndex := 0
 	emitSlot := func() *entity.GroupSlot {
-		user := usersSettings[currentEmittedUserIndex]
-		currentEmittedUserIndex++
+		shuffleSlots(rnd, slotsBucket)
 
-		if currentEmittedUserIndex == len(usersSettings) {
-			currentEmittedUserIndex = 0
-		}
+		for {
+			user := usersSettings[currentEmittedUserIndex]
+			currentEmittedUserIndex++
 
-		shuffleSlots(slotsBucket)
+			if currentEmittedUserIndex == len(usersSettings) {
+				currentEmittedUserIndex = 0
+			}
 
-		for i := range slotsBucket {
-			if slotsBucket[i].UserID() == user.userID {
-				slot := slotsBucket[i]
-				slotsBucket = removeSlotByIndex(slotsBucket, i)
+			for i := range slotsBucket {
+				if slotsBucket[i].UserID() == user.userID {
+					slot := slotsBucket[i]
+					slotsBucket = removeSlotByIndex(slotsBucket, i)
 
-				return slot
+					return slot
+				}
 			}
 		}
-
-		return nil
 	}
 
 	for i := range groups {
-		currentSlotsIndex := 0
+		for slotIndex := range groups[i].TeamSlots() {
+			groups[i].AssignSlot(slotIndex, emitSlot())
+		}
+	}
+}
 
-		for !isGroupFullFilled(groups[i]) {
-			slot := emitSlot()
-			groups[i].AssignSlot(currentSlotsIndex, slot)
-			currentSlotsIndex++
+// fillGroupsFromPots splits slots into pots by team rating, every group gets at most one slot from each pot.
+func fillGroupsFromPots(
+	rnd *rand.Rand,
+	groups []*entity.Group,
+	slotsBucket []*entity.GroupSlot,
+	teamsByID map[string]dto.Team,
+) map[*entity.GroupSlot]int {
+	potBySlot := splitIntoPots(rnd, slotsBucket, len(groups), teamsByID)
+
+	for potIndex, pot := range splitSlots(slotsBucket, len(groups)) {
+		// the last pot of uneven groups goes to the groups having a free slot for it.
+		i := 0
+
+		for _, group := range groups {
+			if potIndex < group.Size() {
+				group.AssignSlot(potIndex, pot[i])
+				i++
+			}
 		}
 	}
+
+	return potBySlot
+}
+
+// splitSlots cuts slots into consecutive chunks of the size, the last chunk may be smaller.
+func splitSlots(slots []*entity.GroupSlot, size int) [][]*entity.GroupSlot {
+	var chunks [][]*entity.GroupSlot
+
+	for start := 0; start < len(slots); start += size {
+		end := start + size
+		if end > len(slots) {
+			end = len(slots)
+		}
+
+		chunks = append(chunks, slots[start:end])
+	}
+
+	return chunks
+}
+
+// splitIntoPots orders slots by team rating, so every consecutive potSize slots make a pot.
+func splitIntoPots(
+	rnd *rand.Rand,
+	slotsBucket []*entity.GroupSlot,
+	potSize int,
+	teamsByID map[string]dto.Team,
+) map[*entity.GroupSlot]int {
+	shuffleSlots(rnd, slotsBucket)
+
+	sort.SliceStable(slotsBucket, func(i, j int) bool {
+		return teamsByID[slotsBucket[i].TeamID()].Rating > teamsByID[slotsBucket[j].TeamID()].Rating
+	})
+
+	potBySlot := make(map[*entity.GroupSlot]int, len(slotsBucket))
+
+	for potIndex, pot := range splitSlots(slotsBucket, potSize) {
+		shuffleSlots(rnd, pot)
+
+		for _, slot := range pot {
+			potBySlot[slot] = potIndex
+		}
+	}
+
+	return potBySlot
+}
+
+func drawGroups(
+	rnd *rand.Rand,
+	settings *TourneySettingsDTO,
+	groups []*entity.Group,
+	usersSettings []*UserSettingsDTO,
+	slotsCountByUserID map[string]int,
+	slotsBucket []*entity.GroupSlot,
+	teamsByID map[string]dto.Team,
+) ([]Constraint, error) {
+	var constraints []Constraint
+
+	names := settings.constraintNames()
+
+	orderKey := func(slot *entity.GroupSlot) string {
+		return slot.UserID()
+	}
+
+	if names[ConstraintLeagueProtection] {
+		leagueProtection := settings.leagueProtection
+		if leagueProtection == LeagueProtectionNone {
+			leagueProtection = LeagueProtectionLeague
+		}
+
+		leagueKey := leagueKeyFunc(leagueProtection, teamsByID)
+
+		constraint, err := newLeagueProtectionConstraint(slotsBucket, len(groups), leagueKey)
+		if err != nil {
+			return nil, fmt.Errorf("checking league protection error: %w", err)
+		}
+
+		constraints = append(constraints, constraint)
+		orderKey = leagueKey
+	}
+
+	if names[ConstraintUserSpread] {
+		constraint, err := newUserSpreadConstraint(slotsCountByUserID, len(groups), settings.maxUserSlotsPerGroup)
+		if err != nil {
+			return nil, fmt.Errorf("checking user spread error: %w", err)
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	if names[ConstraintRivalSeparation] && len(settings.rivals) > 0 {
+		constraint, err := newRivalSeparationConstraint(settings.rivals, slotsBucket, len(groups))
+		if err != nil {
+			return nil, fmt.Errorf("checking rival separation error: %w", err)
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	if len(constraints) == 0 {
+		if names[ConstraintPots] {
+			constraints = append(constraints, potsConstraint{potBySlot: fillGroupsFromPots(rnd, groups, slotsBucket, teamsByID)})
+		} else {
+			fillGroups(rnd, groups, usersSettings, slotsBucket)
+		}
+
+		return constraints, nil
+	}
+
+	if names[ConstraintPots] {
+		constraints = append(constraints, potsConstraint{potBySlot: splitIntoPots(rnd, slotsBucket, len(groups), teamsByID)})
+
+		for _, pot := range splitSlots(slotsBucket, len(groups)) {
+			sortSlotsByKeyFrequency(pot, orderKey)
+		}
+	} else {
+		shuffleSlots(rnd, slotsBucket)
+		sortSlotsByKeyFrequency(slotsBucket, orderKey)
+	}
+
+	if err := placeSlots(rnd, groups, slotsBucket, constraints); err != nil {
+		return nil, fmt.Errorf("placing slots error: %w", err)
+	}
+
+	return constraints, nil
+}
+
+// distributeSlots resolves the number of slots for every user. Users with an explicit teams count
+// get exactly that many slots, users without one (zero teams count) share the remaining slots evenly.
+func distributeSlots(usersSettings []*UserSettingsDTO, slotsCount int) (map[string]int, error) {
+	if len(usersSettings) == 0 {
+		return nil, settingsError("users", errSlotsDistribution, "at least one user is required")
+	}
+
+	slotsCountByUserID := make(map[string]int, len(usersSettings))
+	requestedSlotsCount := 0
+
+	var autoUserIDs []string
+
+	for i, settings := range usersSettings {
+		if _, exists := slotsCountByUserID[settings.userID]; exists {
+			return nil, settingsError(fmt.Sprintf("users[%d].user_id", i), errSlotsDistribution, "`%s` is given more than once", settings.userID)
+		}
+
+		switch {
+		case settings.teamsCount < 0:
+			return nil, settingsError(
+				fmt.Sprintf("users[%d].teams_count", i), errSlotsDistribution, "must not be negative, got %d", settings.teamsCount,
+			)
+		case settings.teamsCount == 0:
+			autoUserIDs = append(autoUserIDs, settings.userID)
+		}
+
+		slotsCountByUserID[settings.userID] = settings.teamsCount
+		requestedSlotsCount += settings.teamsCount
+	}
+
+	remainingSlotsCount := slotsCount - requestedSlotsCount
+
+	switch {
+	case remainingSlotsCount < 0, len(autoUserIDs) == 0 && remainingSlotsCount > 0:
+		return nil, settingsError(
+			"users", errSlotsDistribution, "users request %d teams, tourney has %d slots", requestedSlotsCount, slotsCount,
+		)
+	case len(autoUserIDs) > remainingSlotsCount:
+		return nil, settingsError(
+			"users", errSlotsDistribution, "%d remaining slots can't be shared by %d users", remainingSlotsCount, len(autoUserIDs),
+		)
+	}
+
+	for i, userID := range autoUserIDs {
+		slotsCountByUserID[userID] = remainingSlotsCount / len(autoUserIDs)
+		if i < remainingSlotsCount%len(autoUserIDs) {
+			slotsCountByUserID[userID]++
+		}
+	}
+
+	for i, settings := range usersSettings {
+		if len(settings.requiredTeamIDs) > slotsCountByUserID[settings.userID] {
+			return nil, settingsError(
+				fmt.Sprintf("users[%d].required_teams", i), errSlotsDistribution,
+				"%d required teams exceed the %d slots the user gets", len(settings.requiredTeamIDs), slotsCountByUserID[settings.userID],
+			)
+		}
+	}
+
+	return slotsCountByUserID, nil
 }
 
 func groupTeams(usersSettings []*UserSettingsDTO, requiredTeams []dto.Team) (map[string][]dto.Team, error) {
@@ -202,6 +1194,143 @@ func groupTeams(usersSettings []*UserSettingsDTO, requiredTeams []dto.Team) (map
 	return requiredTeamsGroupedByUserID, nil
 }
 
+// resolveRequiredTeams settles the teams required by several users by the policy, the users who lose
+// a team get copies of their settings without it.
+func resolveRequiredTeams(
+	rnd *rand.Rand,
+	usersSettings []*UserSettingsDTO,
+	policy string,
+) ([]*UserSettingsDTO, []*entity.ContestedTeam, error) {
+	var teamIDs []string
+
+	claimantIDsByTeamID := make(map[string][]string)
+
+	for _, settings := range usersSettings {
+		for _, teamID := range settings.requiredTeamIDs {
+			if _, exists := claimantIDsByTeamID[teamID]; !exists {
+				teamIDs = append(teamIDs, teamID)
+			}
+
+			claimantIDsByTeamID[teamID] = append(claimantIDsByTeamID[teamID], settings.userID)
+		}
+	}
+
+	var contestedTeams []*entity.ContestedTeam
+
+	lostTeamIDsByUserID := make(map[string][]string)
+
+	for _, teamID := range teamIDs {
+		claimantIDs := claimantIDsByTeamID[teamID]
+		if len(claimantIDs) < 2 {
+			continue
+		}
+
+		var ownerIDs []string
+
+		switch policy {
+		case RequiredTeamsPolicyFirstCome:
+			ownerIDs = claimantIDs[:1]
+		case RequiredTeamsPolicyRandomWinner:
+			ownerIDs = []string{claimantIDs[rnd.Intn(len(claimantIDs))]}
+		case RequiredTeamsPolicyDuplicateAllowed:
+			ownerIDs = claimantIDs
+		default:
+			return nil, nil, settingsError(
+				"required_teams_policy", errRequiredTeams, "`%s` rejects team `%s` required by users %v", policy, teamID, claimantIDs,
+			)
+		}
+
+		for _, claimantID := range claimantIDs {
+			if !goFunk.ContainsString(ownerIDs, claimantID) {
+				lostTeamIDsByUserID[claimantID] = append(lostTeamIDsByUserID[claimantID], teamID)
+			}
+		}
+
+		contestedTeams = append(contestedTeams, entity.NewContestedTeam(teamID, claimantIDs, ownerIDs))
+	}
+
+	resolvedUsersSettings := make([]*UserSettingsDTO, len(usersSettings))
+
+	for i, settings := range usersSettings {
+		resolvedUsersSettings[i] = settings
+
+		if lostTeamIDs := lostTeamIDsByUserID[settings.userID]; len(lostTeamIDs) > 0 {
+			resolvedUsersSettings[i] = NewUserSettingsDTO(
+				settings.userID, settings.teamsCount, goFunk.SubtractString(settings.requiredTeamIDs, lostTeamIDs),
+			)
+		}
+	}
+
+	return resolvedUsersSettings, contestedTeams, nil
+}
+
+// missingTeamIDs returns the requested IDs missing among the found teams.
+func missingTeamIDs(requestedTeamIDs []string, foundTeams []dto.Team) []string {
+	foundTeamIDs := make(map[string]bool, len(foundTeams))
+	for _, team := range foundTeams {
+		foundTeamIDs[team.ID] = true
+	}
+
+	var missingIDs []string
+
+	for _, teamID := range requestedTeamIDs {
+		if !foundTeamIDs[teamID] && !goFunk.ContainsString(missingIDs, teamID) {
+			missingIDs = append(missingIDs, teamID)
+		}
+	}
+
+	return missingIDs
+}
+
+// popSimilarTeam takes the pool team rated closest to the average of the user's required teams,
+// or to the max rating when the user has none, ties are broken randomly. The pool must not be empty.
+func popSimilarTeam(rnd *rand.Rand, pool, userRequiredTeams []dto.Team, maxRating float64) (dto.Team, []dto.Team) {
+	targetRating := maxRating
+
+	if len(userRequiredTeams) > 0 {
+		ratingTotal := 0.0
+		for _, team := range userRequiredTeams {
+			ratingTotal += team.Rating
+		}
+
+		targetRating = ratingTotal / float64(len(userRequiredTeams))
+	}
+
+	var closestIndexes []int
+
+	for i, team := range pool {
+		if len(closestIndexes) > 0 {
+			closest := math.Abs(pool[closestIndexes[0]].Rating - targetRating)
+
+			switch distance := math.Abs(team.Rating - targetRating); {
+			case distance > closest:
+				continue
+			case distance < closest:
+				closestIndexes = closestIndexes[:0]
+			}
+		}
+
+		closestIndexes = append(closestIndexes, i)
+	}
+
+	index := closestIndexes[rnd.Intn(len(closestIndexes))]
+	team := pool[index]
+
+	return team, append(pool[:index:index], pool[index+1:]...)
+}
+
+func unknownRequiredTeamsCount(usersSettings []*UserSettingsDTO, unknownTeamIDs []string) (count int) {
+	for _, settings := range usersSettings {
+		for _, teamID := range settings.requiredTeamIDs {
+			if goFunk.ContainsString(unknownTeamIDs, teamID) {
+				count++
+			}
+		}
+	}
+
+	return
+}
+
 func requiredTeamIDsFromUserSettings(usersSettings []*UserSettingsDTO) []string {
 	var requiredTeamIDs []string
 	for _, settings := range usersSettings {
@@ -211,33 +1340,28 @@ func requiredTeamIDsFromUserSettings(usersSettings []*UserSettingsDTO) []string
 	return requiredTeamIDs
 }
 
-func generateShuffledGroups(count, teamsPerGroup int) []*entity.Group {
-	groups := make([]*entity.Group, count)
-	startASCII := 97
+func generateShuffledGroups(rnd *rand.Rand, names []string, sizes []int) []*entity.Group {
+	groups := make([]*entity.Group, len(names))
 
-	for i := 0; i < count; i++ {
-		name := string(rune(startASCII + i))
-		groups[i] = entity.NewGroup(name, make([]*entity.GroupSlot, teamsPerGroup))
+	for i, name := range names {
+		groups[i] = entity.NewGroup(name, make([]*entity.GroupSlot, sizes[i]))
 	}
 
-	rand.Seed(time.Now().UnixMicro())
-	rand.Shuffle(len(groups), func(i, j int) {
+	rnd.Shuffle(len(groups), func(i, j int) {
 		groups[i], groups[j] = groups[j], groups[i]
 	})
 
 	return groups
 }
 
-func shuffleSlots(slots []*entity.GroupSlot) {
-	rand.Seed(time.Now().UnixMicro())
-	rand.Shuffle(len(slots), func(i, j int) {
+func shuffleSlots(rnd *rand.Rand, slots []*entity.GroupSlot) {
+	rnd.Shuffle(len(slots), func(i, j int) {
 		slots[i], slots[j] = slots[j], slots[i]
 	})
 }
 
-func shuffleTeams(teams []dto.Team) {
-	rand.Seed(time.Now().UnixMicro())
-	rand.Shuffle(len(teams), func(i, j int) {
+func shuffleTeams(rnd *rand.Rand, teams []dto.Team) {
+	rnd.Shuffle(len(teams), func(i, j int) {
 		teams[i], teams[j] = teams[j], teams[i]
 	})
 }
@@ -275,13 +1399,30 @@ func groupTeamsByRating(teams []dto.Team) [][]dto.Team {
 	return groupedTeams
 }
 
-func normalizeGroups(groups []*entity.Group) []*entity.Group {
+func indexTeams(teamsCollections ...[]dto.Team) map[string]dto.Team {
+	teamsByID := make(map[string]dto.Team)
+
+	for _, teams := range teamsCollections {
+		for _, team := range teams {
+			teamsByID[team.ID] = team
+		}
+	}
+
+	return teamsByID
+}
+
+func normalizeGroups(rnd *rand.Rand, groups []*entity.Group, names []string) []*entity.Group {
 	for i := range groups {
-		shuffleSlots(groups[i].TeamSlots())
+		shuffleSlots(rnd, groups[i].TeamSlots())
+	}
+
+	indexByName := make(map[string]int, len(names))
+	for i, name := range names {
+		indexByName[name] = i
 	}
 
 	sort.Slice(groups, func(i, j int) bool {
-		return groups[i].Name() < groups[j].Name()
+		return indexByName[groups[i].Name()] < indexByName[groups[j].Name()]
 	})
 
 	return groups
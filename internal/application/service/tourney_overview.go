@@ -0,0 +1,26 @@
+package service
+
+import "github.com/twizar/tourneys/internal/domain/entity"
+
+// TourneyOverview is a tourney along with the report of its draw and the standings of its groups,
+// in the order of the groups.
+type TourneyOverview struct {
+	Tourney   *entity.Tourney
+	Report    *DrawReport
+	Standings [][]StandingRow
+}
+
+func newTourneyOverview(tourney *entity.Tourney, report *DrawReport) *TourneyOverview {
+	return &TourneyOverview{Tourney: tourney, Report: report, Standings: tourneyStandings(tourney)}
+}
+
+// RecordResults adds the results to the tourney and ranks its groups again.
+func (o *TourneyOverview) RecordResults(results []*MatchResultDTO) error {
+	if err := RecordResults(o.Tourney, results); err != nil {
+		return err
+	}
+
+	o.Standings = tourneyStandings(o.Tourney)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDrawRevealed      = errors.New("draw has already been revealed")
+	ErrClientSeedExists  = errors.New("client seed has already been contributed")
+	ErrClientSeedInvalid = errors.New("client seed is invalid")
+)
+
+type ClientSeed struct {
+	userID,
+	seed string
+}
+
+func NewClientSeed(userID, seed string) *ClientSeed {
+	return &ClientSeed{userID: userID, seed: seed}
+}
+
+func (cs ClientSeed) UserID() string {
+	return cs.userID
+}
+
+func (cs ClientSeed) Seed() string {
+	return cs.seed
+}
+
+type DrawCommitment struct {
+	id          string
+	serverSeed  string
+	commitment  string
+	clientSeeds []*ClientSeed
+	revealed    bool
+	version     int
+}
+
+func NewDrawCommitment(id, serverSeed, commitment string) *DrawCommitment {
+	return &DrawCommitment{id: id, serverSeed: serverSeed, commitment: commitment}
+}
+
+// RestoreDrawCommitment brings back a stored draw with its client seeds and state.
+func RestoreDrawCommitment(id, serverSeed, commitment string, clientSeeds []*ClientSeed, revealed bool, version int) *DrawCommitment {
+	return &DrawCommitment{
+		id:          id,
+		serverSeed:  serverSeed,
+		commitment:  commitment,
+		clientSeeds: clientSeeds,
+		revealed:    revealed,
+		version:     version,
+	}
+}
+
+func (dc DrawCommitment) ID() string {
+	return dc.id
+}
+
+func (dc DrawCommitment) ServerSeed() string {
+	return dc.serverSeed
+}
+
+func (dc DrawCommitment) Commitment() string {
+	return dc.commitment
+}
+
+func (dc DrawCommitment) ClientSeeds() []*ClientSeed {
+	return dc.clientSeeds
+}
+
+func (dc DrawCommitment) Revealed() bool {
+	return dc.revealed
+}
+
+// Version counts the stored changes of the draw, zero for a draw which hasn't been stored yet.
+func (dc DrawCommitment) Version() int {
+	return dc.version
+}
+
+func (dc *DrawCommitment) AssignVersion(version int) {
+	dc.version = version
+}
+
+func (dc *DrawCommitment) AddClientSeed(clientSeed *ClientSeed) error {
+	if dc.revealed {
+		return fmt.Errorf("adding client seed to draw `%s` error: %w", dc.id, ErrDrawRevealed)
+	}
+
+	if clientSeed.userID == "" || clientSeed.seed == "" {
+		return fmt.Errorf("user ID and seed are required: %w", ErrClientSeedInvalid)
+	}
+
+	for _, existing := range dc.clientSeeds {
+		if existing.userID == clientSeed.userID {
+			return fmt.Errorf("user `%s` error: %w", clientSeed.userID, ErrClientSeedExists)
+		}
+	}
+
+	dc.clientSeeds = append(dc.clientSeeds, clientSeed)
+
+	return nil
+}
+
+func (dc *DrawCommitment) Reveal() error {
+	if dc.revealed {
+		return fmt.Errorf("revealing draw `%s` error: %w", dc.id, ErrDrawRevealed)
+	}
+
+	dc.revealed = true
+
+	return nil
+}
+
+// Reopen takes back a reveal nobody has seen.
+func (dc *DrawCommitment) Reopen() {
+	dc.revealed = false
+}
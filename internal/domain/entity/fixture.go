@@ -0,0 +1,45 @@
+package entity
+
+// NoBye marks a matchday where every slot of the group plays.
+const NoBye = -1
+
+// Fixture is a match between two slots of a group given by their indexes in the group.
+type Fixture struct {
+	homeSlotIndex,
+	awaySlotIndex int
+}
+
+func NewFixture(homeSlotIndex, awaySlotIndex int) *Fixture {
+	return &Fixture{homeSlotIndex: homeSlotIndex, awaySlotIndex: awaySlotIndex}
+}
+
+func (f Fixture) HomeSlotIndex() int {
+	return f.homeSlotIndex
+}
+
+func (f Fixture) AwaySlotIndex() int {
+	return f.awaySlotIndex
+}
+
+type Matchday struct {
+	number       int
+	fixtures     []*Fixture
+	byeSlotIndex int
+}
+
+func NewMatchday(number int, fixtures []*Fixture, byeSlotIndex int) *Matchday {
+	return &Matchday{number: number, fixtures: fixtures, byeSlotIndex: byeSlotIndex}
+}
+
+func (m Matchday) Number() int {
+	return m.number
+}
+
+func (m Matchday) Fixtures() []*Fixture {
+	return m.fixtures
+}
+
+// ByeSlotIndex returns the index of the slot resting on the matchday, NoBye when every slot plays.
+func (m Matchday) ByeSlotIndex() int {
+	return m.byeSlotIndex
+}
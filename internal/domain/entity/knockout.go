@@ -0,0 +1,102 @@
+package entity
+
+// KnockoutSlot is a side of a knockout tie: a group qualifier or the winner or the loser of an earlier tie.
+type KnockoutSlot struct {
+	groupName string
+	position  int
+	seed      int
+	tieNumber int
+	loser     bool
+}
+
+// NewQualifierSlot takes the team finishing the group at the position, positions start with 1.
+func NewQualifierSlot(groupName string, position, seed int) *KnockoutSlot {
+	return &KnockoutSlot{groupName: groupName, position: position, seed: seed}
+}
+
+func NewWinnerSlot(tieNumber int) *KnockoutSlot {
+	return &KnockoutSlot{tieNumber: tieNumber}
+}
+
+func NewLoserSlot(tieNumber int) *KnockoutSlot {
+	return &KnockoutSlot{tieNumber: tieNumber, loser: true}
+}
+
+func (ks KnockoutSlot) GroupName() string {
+	return ks.groupName
+}
+
+func (ks KnockoutSlot) Position() int {
+	return ks.position
+}
+
+func (ks KnockoutSlot) Seed() int {
+	return ks.seed
+}
+
+// TieNumber returns the number of the earlier tie the slot comes from, 0 for a group qualifier.
+func (ks KnockoutSlot) TieNumber() int {
+	return ks.tieNumber
+}
+
+func (ks KnockoutSlot) Loser() bool {
+	return ks.loser
+}
+
+type KnockoutTie struct {
+	number int
+	home,
+	away *KnockoutSlot
+}
+
+func NewKnockoutTie(number int, home, away *KnockoutSlot) *KnockoutTie {
+	return &KnockoutTie{number: number, home: home, away: away}
+}
+
+func (kt KnockoutTie) Number() int {
+	return kt.number
+}
+
+func (kt KnockoutTie) Home() *KnockoutSlot {
+	return kt.home
+}
+
+func (kt KnockoutTie) Away() *KnockoutSlot {
+	return kt.away
+}
+
+type KnockoutRound struct {
+	name string
+	ties []*KnockoutTie
+}
+
+func NewKnockoutRound(name string, ties []*KnockoutTie) *KnockoutRound {
+	return &KnockoutRound{name: name, ties: ties}
+}
+
+func (kr KnockoutRound) Name() string {
+	return kr.name
+}
+
+func (kr KnockoutRound) Ties() []*KnockoutTie {
+	return kr.ties
+}
+
+// KnockoutStage is the bracket played after the groups, the last round is the final.
+type KnockoutStage struct {
+	rounds     []*KnockoutRound
+	thirdPlace *KnockoutTie
+}
+
+func NewKnockoutStage(rounds []*KnockoutRound, thirdPlace *KnockoutTie) *KnockoutStage {
+	return &KnockoutStage{rounds: rounds, thirdPlace: thirdPlace}
+}
+
+func (ks KnockoutStage) Rounds() []*KnockoutRound {
+	return ks.rounds
+}
+
+// ThirdPlace returns the match of the semi-finals losers, nil when it isn't played.
+func (ks KnockoutStage) ThirdPlace() *KnockoutTie {
+	return ks.thirdPlace
+}
@@ -0,0 +1,47 @@
+package entity
+
+// Match is the result of a played fixture between two slots of a group given by their indexes in the group.
+type Match struct {
+	homeSlotIndex,
+	awaySlotIndex,
+	homeGoals,
+	awayGoals,
+	homeFairPlayPoints,
+	awayFairPlayPoints int
+}
+
+func NewMatch(homeSlotIndex, awaySlotIndex, homeGoals, awayGoals int) *Match {
+	return &Match{homeSlotIndex: homeSlotIndex, awaySlotIndex: awaySlotIndex, homeGoals: homeGoals, awayGoals: awayGoals}
+}
+
+// WithFairPlayPoints sets the disciplinary penalty points of the sides.
+func (m *Match) WithFairPlayPoints(homeFairPlayPoints, awayFairPlayPoints int) *Match {
+	m.homeFairPlayPoints = homeFairPlayPoints
+	m.awayFairPlayPoints = awayFairPlayPoints
+
+	return m
+}
+
+func (m Match) HomeSlotIndex() int {
+	return m.homeSlotIndex
+}
+
+func (m Match) AwaySlotIndex() int {
+	return m.awaySlotIndex
+}
+
+func (m Match) HomeGoals() int {
+	return m.homeGoals
+}
+
+func (m Match) AwayGoals() int {
+	return m.awayGoals
+}
+
+func (m Match) HomeFairPlayPoints() int {
+	return m.homeFairPlayPoints
+}
+
+func (m Match) AwayFairPlayPoints() int {
+	return m.awayFairPlayPoints
+}
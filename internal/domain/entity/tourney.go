@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type GroupSlot struct {
 	userID,
 	teamID string
@@ -20,6 +22,8 @@ func (u GroupSlot) TeamID() string {
 type Group struct {
 	name      string
 	teamSlots []*GroupSlot
+	matchdays []*Matchday
+	matches   []*Match
 }
 
 func NewGroup(name string, teamSlots []*GroupSlot) *Group {
@@ -38,17 +42,134 @@ func (g Group) TeamSlots() []*GroupSlot {
 	return g.teamSlots
 }
 
+func (g Group) Size() int {
+	return len(g.teamSlots)
+}
+
+func (g Group) Matchdays() []*Matchday {
+	return g.matchdays
+}
+
+func (g *Group) AssignMatchdays(matchdays []*Matchday) {
+	g.matchdays = matchdays
+}
+
+func (g Group) Matches() []*Match {
+	return g.matches
+}
+
+func (g *Group) AddMatch(match *Match) {
+	g.matches = append(g.matches, match)
+}
+
+// ContestedTeam is a team required by several users, owners are the claimants who got it.
+type ContestedTeam struct {
+	teamID      string
+	claimantIDs []string
+	ownerIDs    []string
+}
+
+func NewContestedTeam(teamID string, claimantIDs, ownerIDs []string) *ContestedTeam {
+	return &ContestedTeam{teamID: teamID, claimantIDs: claimantIDs, ownerIDs: ownerIDs}
+}
+
+func (ct ContestedTeam) TeamID() string {
+	return ct.teamID
+}
+
+func (ct ContestedTeam) ClaimantIDs() []string {
+	return ct.claimantIDs
+}
+
+func (ct ContestedTeam) OwnerIDs() []string {
+	return ct.ownerIDs
+}
+
+// TeamSubstitution tells the user got another team instead of the unknown required one.
+type TeamSubstitution struct {
+	userID          string
+	requestedTeamID string
+	teamID          string
+}
+
+func NewTeamSubstitution(userID, requestedTeamID, teamID string) *TeamSubstitution {
+	return &TeamSubstitution{userID: userID, requestedTeamID: requestedTeamID, teamID: teamID}
+}
+
+func (ts TeamSubstitution) UserID() string {
+	return ts.userID
+}
+
+func (ts TeamSubstitution) RequestedTeamID() string {
+	return ts.requestedTeamID
+}
+
+func (ts TeamSubstitution) TeamID() string {
+	return ts.teamID
+}
+
+// TeamsPool tells the rating range the teams were drawn from and how many teams it yielded,
+// the min rating is the one the search ended at when lower ratings are allowed.
+type TeamsPool struct {
+	minRating float64
+	maxRating float64
+	size      int
+}
+
+func NewTeamsPool(minRating, maxRating float64, size int) *TeamsPool {
+	return &TeamsPool{minRating: minRating, maxRating: maxRating, size: size}
+}
+
+func (tp TeamsPool) MinRating() float64 {
+	return tp.minRating
+}
+
+func (tp TeamsPool) MaxRating() float64 {
+	return tp.maxRating
+}
+
+func (tp TeamsPool) Size() int {
+	return tp.size
+}
+
 type Tourney struct {
-	id            string
-	groupsCount   int
-	teamsPerGroup int
-	groups        []*Group
+	id             string
+	groupsCount    int
+	teamsPerGroup  int
+	seed           int64
+	groups         []*Group
+	contestedTeams []*ContestedTeam
+	substitutions  []*TeamSubstitution
+	knockout       *KnockoutStage
+	tiebreakers    []string
+	pool           *TeamsPool
+	createdAt      time.Time
+	version        int
 }
 
 func (t Tourney) ID() string {
 	return t.id
 }
 
+func (t Tourney) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// AssignID identifies the drawn tourney once it's chosen to be stored.
+func (t *Tourney) AssignID(id string, createdAt time.Time) {
+	t.id = id
+	t.createdAt = createdAt
+}
+
+// Version counts the stored changes of the tourney, zero for a tourney which hasn't been stored yet.
+func (t Tourney) Version() int {
+	return t.version
+}
+
+func (t *Tourney) AssignVersion(version int) {
+	t.version = version
+}
+
 func (t Tourney) GroupsCount() int {
 	return t.groupsCount
 }
@@ -57,10 +178,75 @@ func (t Tourney) TeamsPerGroup() int {
 	return t.teamsPerGroup
 }
 
+func (t Tourney) Seed() int64 {
+	return t.seed
+}
+
 func (t Tourney) Groups() []*Group {
 	return t.groups
 }
 
-func NewTourney(id string, groupsCount, teamsPerGroup int, groups []*Group) *Tourney {
-	return &Tourney{id: id, groupsCount: groupsCount, teamsPerGroup: teamsPerGroup, groups: groups}
+// UserIDs returns the users having slots in the tourney in the order of their first slots.
+func (t Tourney) UserIDs() []string {
+	var userIDs []string
+
+	seen := make(map[string]bool)
+
+	for _, group := range t.groups {
+		for _, slot := range group.teamSlots {
+			if !seen[slot.userID] {
+				seen[slot.userID] = true
+				userIDs = append(userIDs, slot.userID)
+			}
+		}
+	}
+
+	return userIDs
+}
+
+func (t Tourney) ContestedTeams() []*ContestedTeam {
+	return t.contestedTeams
+}
+
+func (t Tourney) Substitutions() []*TeamSubstitution {
+	return t.substitutions
+}
+
+// Knockout returns the bracket following the groups, nil when the tourney has no knockout stage.
+func (t Tourney) Knockout() *KnockoutStage {
+	return t.knockout
+}
+
+func (t *Tourney) AssignKnockout(knockout *KnockoutStage) {
+	t.knockout = knockout
+}
+
+// Tiebreakers returns the ordered criteria ranking the group standings.
+func (t Tourney) Tiebreakers() []string {
+	return t.tiebreakers
+}
+
+func (t *Tourney) AssignTiebreakers(tiebreakers []string) {
+	t.tiebreakers = tiebreakers
+}
+
+// Pool returns the teams pool the tourney was drawn from, nil for the tourneys stored without it.
+func (t Tourney) Pool() *TeamsPool {
+	return t.pool
+}
+
+func (t *Tourney) AssignPool(pool *TeamsPool) {
+	t.pool = pool
+}
+
+func (t *Tourney) AddSubstitution(substitution *TeamSubstitution) {
+	t.substitutions = append(t.substitutions, substitution)
+}
+
+func (t *Tourney) AddContestedTeam(contestedTeam *ContestedTeam) {
+	t.contestedTeams = append(t.contestedTeams, contestedTeam)
+}
+
+func NewTourney(id string, groupsCount, teamsPerGroup int, seed int64, groups []*Group) *Tourney {
+	return &Tourney{id: id, groupsCount: groupsCount, teamsPerGroup: teamsPerGroup, seed: seed, groups: groups}
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+var ErrDrawNotFound = errors.New("draw not found")
+
+// DrawRepository stores the fair draws, so every instance of the service sees the same draws.
+type DrawRepository interface {
+	// Save stores the draw over the version it was loaded at and assigns it the next version, it returns
+	// ErrVersionConflict when the stored draw has changed meanwhile or a new draw's ID is taken.
+	Save(draw *entity.DrawCommitment) error
+	// Get returns ErrDrawNotFound when there is no draw with the ID.
+	Get(id string) (*entity.DrawCommitment, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+var (
+	ErrTourneyNotFound = errors.New("tourney not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	// ErrVersionConflict tells the stored state has changed since it was loaded.
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrTourneyTooLarge tells the tourney exceeds the size the storage takes.
+	ErrTourneyTooLarge = errors.New("tourney too large")
+)
+
+// TourneyRepository stores the generated tourneys by their IDs.
+type TourneyRepository interface {
+	// Save stores the tourney over the version it was loaded at and assigns it the next version, it returns
+	// ErrVersionConflict when the stored tourney has changed or has been deleted meanwhile, or a new tourney's ID is taken,
+	// and ErrTourneyTooLarge when the storage doesn't take the tourney.
+	Save(tourney *entity.Tourney) error
+	// Get returns ErrTourneyNotFound when there is no tourney with the ID.
+	Get(id string) (*entity.Tourney, error)
+	// ListByUser pages the tourneys having slots of the user in the order they were created. The next cursor
+	// continues after the returned page, it's empty on the last page.
+	ListByUser(userID, cursor string, limit int) (tourneys []*entity.Tourney, nextCursor string, err error)
+	// Delete returns ErrTourneyNotFound when there is no tourney with the ID.
+	Delete(id string) error
+}
@@ -0,0 +1,35 @@
+package converter
+
+import "github.com/twizar/tourneys/internal/domain/entity"
+
+type ClientSeedDTO struct {
+	UserID string `json:"user_id"`
+	Seed   string `json:"seed"`
+}
+
+type DrawDTO struct {
+	ID          string          `json:"id,omitempty"`
+	Commitment  string          `json:"commitment"`
+	ServerSeed  string          `json:"server_seed,omitempty"`
+	ClientSeeds []ClientSeedDTO `json:"client_seeds"`
+}
+
+// DrawCommitmentEntityToDTO exposes the server seed only once the draw has been revealed.
+func DrawCommitmentEntityToDTO(draw *entity.DrawCommitment) DrawDTO {
+	clientSeeds := make([]ClientSeedDTO, len(draw.ClientSeeds()))
+	for i, clientSeed := range draw.ClientSeeds() {
+		clientSeeds[i] = ClientSeedDTO{UserID: clientSeed.UserID(), Seed: clientSeed.Seed()}
+	}
+
+	drawDTO := DrawDTO{
+		ID:          draw.ID(),
+		Commitment:  draw.Commitment(),
+		ClientSeeds: clientSeeds,
+	}
+
+	if draw.Revealed() {
+		drawDTO.ServerSeed = draw.ServerSeed()
+	}
+
+	return drawDTO
+}
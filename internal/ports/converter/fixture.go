@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"github.com/twizar/common/pkg/dto"
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+type FixtureDTO struct {
+	HomeSlot int           `json:"home_slot"`
+	AwaySlot int           `json:"away_slot"`
+	Home     dto.GroupSlot `json:"home"`
+	Away     dto.GroupSlot `json:"away"`
+}
+
+type MatchdayDTO struct {
+	Number   int            `json:"number"`
+	Fixtures []FixtureDTO   `json:"fixtures"`
+	Bye      *dto.GroupSlot `json:"bye,omitempty"`
+}
+
+func matchdayEntitiesToDTOs(matchdays []*entity.Matchday, slots []dto.GroupSlot) []MatchdayDTO {
+	matchdayDTOs := make([]MatchdayDTO, len(matchdays))
+
+	for i, matchday := range matchdays {
+		fixtureDTOs := make([]FixtureDTO, len(matchday.Fixtures()))
+		for j, fixture := range matchday.Fixtures() {
+			fixtureDTOs[j] = FixtureDTO{
+				HomeSlot: fixture.HomeSlotIndex(),
+				AwaySlot: fixture.AwaySlotIndex(),
+				Home:     slots[fixture.HomeSlotIndex()],
+				Away:     slots[fixture.AwaySlotIndex()],
+			}
+		}
+
+		matchdayDTOs[i] = MatchdayDTO{Number: matchday.Number(), Fixtures: fixtureDTOs}
+
+		if matchday.ByeSlotIndex() != entity.NoBye {
+			bye := slots[matchday.ByeSlotIndex()]
+			matchdayDTOs[i].Bye = &bye
+		}
+	}
+
+	return matchdayDTOs
+}
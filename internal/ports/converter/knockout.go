@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+type KnockoutDTO struct {
+	Rounds     []KnockoutRoundDTO `json:"rounds"`
+	ThirdPlace *KnockoutTieDTO    `json:"third_place,omitempty"`
+}
+
+type KnockoutRoundDTO struct {
+	Name string           `json:"name"`
+	Ties []KnockoutTieDTO `json:"ties"`
+}
+
+type KnockoutTieDTO struct {
+	Number int             `json:"number"`
+	Home   KnockoutSlotDTO `json:"home"`
+	Away   KnockoutSlotDTO `json:"away"`
+}
+
+// KnockoutSlotDTO is a placeholder of a team known after the groups or the earlier ties are played.
+type KnockoutSlotDTO struct {
+	Placeholder string `json:"placeholder"`
+	Group       string `json:"group,omitempty"`
+	Position    int    `json:"position,omitempty"`
+	Seed        int    `json:"seed,omitempty"`
+	Tie         int    `json:"tie,omitempty"`
+	Loser       bool   `json:"loser,omitempty"`
+}
+
+func knockoutEntityToDTO(knockout *entity.KnockoutStage) *KnockoutDTO {
+	if knockout == nil {
+		return nil
+	}
+
+	knockoutDTO := &KnockoutDTO{Rounds: make([]KnockoutRoundDTO, len(knockout.Rounds()))}
+
+	for i, round := range knockout.Rounds() {
+		tieDTOs := make([]KnockoutTieDTO, len(round.Ties()))
+		for j, tie := range round.Ties() {
+			tieDTOs[j] = knockoutTieEntityToDTO(tie)
+		}
+
+		knockoutDTO.Rounds[i] = KnockoutRoundDTO{Name: round.Name(), Ties: tieDTOs}
+	}
+
+	if knockout.ThirdPlace() != nil {
+		thirdPlace := knockoutTieEntityToDTO(knockout.ThirdPlace())
+		knockoutDTO.ThirdPlace = &thirdPlace
+	}
+
+	return knockoutDTO
+}
+
+func knockoutTieEntityToDTO(tie *entity.KnockoutTie) KnockoutTieDTO {
+	return KnockoutTieDTO{
+		Number: tie.Number(),
+		Home:   knockoutSlotEntityToDTO(tie.Home()),
+		Away:   knockoutSlotEntityToDTO(tie.Away()),
+	}
+}
+
+func knockoutSlotEntityToDTO(slot *entity.KnockoutSlot) KnockoutSlotDTO {
+	slotDTO := KnockoutSlotDTO{
+		Group:    slot.GroupName(),
+		Position: slot.Position(),
+		Seed:     slot.Seed(),
+		Tie:      slot.TieNumber(),
+		Loser:    slot.Loser(),
+	}
+
+	switch {
+	case slot.TieNumber() != 0 && slot.Loser():
+		slotDTO.Placeholder = fmt.Sprintf("Loser of tie %d", slot.TieNumber())
+	case slot.TieNumber() != 0:
+		slotDTO.Placeholder = fmt.Sprintf("Winner of tie %d", slot.TieNumber())
+	case slot.Position() == 1:
+		slotDTO.Placeholder = fmt.Sprintf("Winner of group %s", slot.GroupName())
+	case slot.Position() == 2:
+		slotDTO.Placeholder = fmt.Sprintf("Runner-up of group %s", slot.GroupName())
+	default:
+		slotDTO.Placeholder = fmt.Sprintf("%s of group %s", ordinal(slot.Position()), slot.GroupName())
+	}
+
+	return slotDTO
+}
+
+func ordinal(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	default:
+		return fmt.Sprintf("%dth", n)
+	}
+}
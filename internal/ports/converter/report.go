@@ -0,0 +1,58 @@
+package converter
+
+import "github.com/twizar/tourneys/internal/application/service"
+
+type UserRatingDTO struct {
+	UserID        string  `json:"user_id"`
+	TeamsCount    int     `json:"teams_count"`
+	RatingTotal   float64 `json:"rating_total"`
+	RatingAverage float64 `json:"rating_average"`
+}
+
+type GroupQualityDTO struct {
+	Name            string  `json:"name"`
+	RatingMean      float64 `json:"rating_mean"`
+	RatingVariance  float64 `json:"rating_variance"`
+	SameLeaguePairs int     `json:"same_league_pairs"`
+	SameUserPairs   int     `json:"same_user_pairs"`
+}
+
+type TeamsPoolDTO struct {
+	MinRating      float64 `json:"min_rating"`
+	MaxRating      float64 `json:"max_rating"`
+	AvailableTeams int     `json:"available_teams"`
+}
+
+type ReportDTO struct {
+	Pool            *TeamsPoolDTO     `json:"pool,omitempty"`
+	Groups          []GroupQualityDTO `json:"groups"`
+	Users           []UserRatingDTO   `json:"users"`
+	SameLeaguePairs int               `json:"same_league_pairs"`
+	SameUserPairs   int               `json:"same_user_pairs"`
+}
+
+func drawReportToDTO(report *service.DrawReport) *ReportDTO {
+	users := make([]UserRatingDTO, len(report.Users))
+	for i, user := range report.Users {
+		users[i] = UserRatingDTO(user)
+	}
+
+	groups := make([]GroupQualityDTO, len(report.Groups))
+	for i, group := range report.Groups {
+		groups[i] = GroupQualityDTO(group)
+	}
+
+	reportDTO := &ReportDTO{
+		Groups:          groups,
+		Users:           users,
+		SameLeaguePairs: report.SameLeaguePairs,
+		SameUserPairs:   report.SameUserPairs,
+	}
+
+	if report.Pool != nil {
+		pool := TeamsPoolDTO(*report.Pool)
+		reportDTO.Pool = &pool
+	}
+
+	return reportDTO
+}
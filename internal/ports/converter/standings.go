@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"github.com/twizar/common/pkg/dto"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+)
+
+type MatchDTO struct {
+	HomeSlot  int `json:"home_slot"`
+	AwaySlot  int `json:"away_slot"`
+	HomeGoals int `json:"home_goals"`
+	AwayGoals int `json:"away_goals"`
+}
+
+type StandingDTO struct {
+	Position       int           `json:"position"`
+	Slot           int           `json:"slot"`
+	Team           dto.GroupSlot `json:"team"`
+	Played         int           `json:"played"`
+	Won            int           `json:"won"`
+	Drawn          int           `json:"drawn"`
+	Lost           int           `json:"lost"`
+	GoalsFor       int           `json:"goals_for"`
+	GoalsAgainst   int           `json:"goals_against"`
+	GoalDifference int           `json:"goal_difference"`
+	Points         int           `json:"points"`
+	AwayGoals      int           `json:"away_goals"`
+	FairPlayPoints int           `json:"fair_play_points"`
+	SeparatedBy    string        `json:"separated_by,omitempty"`
+	Explanation    string        `json:"explanation,omitempty"`
+}
+
+func matchEntitiesToDTOs(matches []*entity.Match) []MatchDTO {
+	matchDTOs := make([]MatchDTO, len(matches))
+
+	for i, match := range matches {
+		matchDTOs[i] = MatchDTO{
+			HomeSlot:  match.HomeSlotIndex(),
+			AwaySlot:  match.AwaySlotIndex(),
+			HomeGoals: match.HomeGoals(),
+			AwayGoals: match.AwayGoals(),
+		}
+	}
+
+	return matchDTOs
+}
+
+func standingsToDTOs(rows []service.StandingRow, slots []dto.GroupSlot) []StandingDTO {
+	standingDTOs := make([]StandingDTO, len(rows))
+
+	for i, row := range rows {
+		standingDTOs[i] = StandingDTO{
+			Position:       i + 1,
+			Slot:           row.SlotIndex,
+			Team:           slots[row.SlotIndex],
+			Played:         row.Played,
+			Won:            row.Won,
+			Drawn:          row.Drawn,
+			Lost:           row.Lost,
+			GoalsFor:       row.GoalsFor,
+			GoalsAgainst:   row.GoalsAgainst,
+			GoalDifference: row.GoalDifference,
+			Points:         row.Points,
+			AwayGoals:      row.AwayGoals,
+			FairPlayPoints: row.FairPlayPoints,
+			SeparatedBy:    row.SeparatedBy,
+			Explanation:    row.Explanation,
+		}
+	}
+
+	return standingDTOs
+}
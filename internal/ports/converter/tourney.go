@@ -3,14 +3,54 @@ package converter
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/twizar/common/pkg/client"
 	"github.com/twizar/common/pkg/dto"
+	"github.com/twizar/tourneys/internal/application/service"
 	"github.com/twizar/tourneys/internal/domain/entity"
 )
 
 var errTeamNotFoundInStorage = errors.New("team hasn't been found in storage")
 
+type TourneyDTO struct {
+	ID            string     `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	GroupsCount   int        `json:"groups_count"`
+	TeamsPerGroup int        `json:"teams_per_group"`
+	Seed          int64      `json:"seed"`
+	Draw          *DrawDTO   `json:"draw,omitempty"`
+	Report        *ReportDTO `json:"report"`
+	Groups        []GroupDTO `json:"groups"`
+	// ContestedTeams lists teams required by several users and who got them.
+	ContestedTeams []ContestedTeamDTO `json:"contested_teams,omitempty"`
+	// Substitutions lists teams given instead of unknown required ones.
+	Substitutions []SubstitutionDTO `json:"substitutions,omitempty"`
+	Knockout      *KnockoutDTO      `json:"knockout,omitempty"`
+}
+
+// GroupDTO extends dto.Group with the group size, groups of a tourney may differ in size.
+type GroupDTO struct {
+	Name      string          `json:"name"`
+	Size      int             `json:"size"`
+	TeamSlots []dto.GroupSlot `json:"team_slots"`
+	Matchdays []MatchdayDTO   `json:"matchdays"`
+	Matches   []MatchDTO      `json:"matches"`
+	Standings []StandingDTO   `json:"standings"`
+}
+
+type SubstitutionDTO struct {
+	UserID          string `json:"user_id"`
+	RequestedTeamID string `json:"requested_team_id"`
+	TeamID          string `json:"team_id"`
+}
+
+type ContestedTeamDTO struct {
+	TeamID      string   `json:"team_id"`
+	ClaimantIDs []string `json:"claimant_ids"`
+	OwnerIDs    []string `json:"owner_ids"`
+}
+
 type Converter struct {
 	teams        client.Teams
 	teamsStorage map[string]dto.Team
@@ -20,45 +60,74 @@ func NewConverter(teams client.Teams) *Converter {
 	return &Converter{teams: teams, teamsStorage: make(map[string]dto.Team)}
 }
 
-func (c Converter) TourneyEntitiesToDTOs(entityTourneys []*entity.Tourney) ([]dto.Tourney, error) {
-	dtoTourneys := make([]dto.Tourney, len(entityTourneys))
+func (c Converter) TourneyOverviewsToDTOs(overviews []*service.TourneyOverview) ([]TourneyDTO, error) {
+	dtoTourneys := make([]TourneyDTO, len(overviews))
+	if len(overviews) == 0 {
+		return dtoTourneys, nil
+	}
 
-	ids := fetchTeamsIDFromTourneysCollection(entityTourneys)
+	ids := fetchTeamsIDFromTourneysCollection(overviews)
 
 	err := c.fillTeamsStorage(ids)
 	if err != nil {
 		return nil, fmt.Errorf("refreshing teams cache error: %w", err)
 	}
 
-	for index, tourney := range entityTourneys {
-		groupDTOs, err := c.groupEntitiesToDTOs(tourney.Groups())
+	for index, overview := range overviews {
+		tourney := overview.Tourney
+
+		groupDTOs, err := c.groupEntitiesToDTOs(tourney.Groups(), overview.Standings)
 		if err != nil {
 			return nil, fmt.Errorf("converting groups error: %w", err)
 		}
 
-		dtoTourneys[index] = dto.Tourney{
+		dtoTourneys[index] = TourneyDTO{
 			ID:            tourney.ID(),
+			CreatedAt:     tourney.CreatedAt(),
 			GroupsCount:   tourney.GroupsCount(),
 			TeamsPerGroup: tourney.TeamsPerGroup(),
+			Seed:          tourney.Seed(),
+			Report:        drawReportToDTO(overview.Report),
 			Groups:        groupDTOs,
+			Knockout:      knockoutEntityToDTO(tourney.Knockout()),
+		}
+
+		for _, contestedTeam := range tourney.ContestedTeams() {
+			dtoTourneys[index].ContestedTeams = append(dtoTourneys[index].ContestedTeams, ContestedTeamDTO{
+				TeamID:      contestedTeam.TeamID(),
+				ClaimantIDs: contestedTeam.ClaimantIDs(),
+				OwnerIDs:    contestedTeam.OwnerIDs(),
+			})
+		}
+
+		for _, substitution := range tourney.Substitutions() {
+			dtoTourneys[index].Substitutions = append(dtoTourneys[index].Substitutions, SubstitutionDTO{
+				UserID:          substitution.UserID(),
+				RequestedTeamID: substitution.RequestedTeamID(),
+				TeamID:          substitution.TeamID(),
+			})
 		}
 	}
 
 	return dtoTourneys, nil
 }
 
-func (c Converter) groupEntitiesToDTOs(entityGroups []*entity.Group) ([]dto.Group, error) {
-	dtoTeams := make([]dto.Group, len(entityGroups))
+func (c Converter) groupEntitiesToDTOs(groups []*entity.Group, standings [][]service.StandingRow) ([]GroupDTO, error) {
+	dtoTeams := make([]GroupDTO, len(groups))
 
-	for index, group := range entityGroups {
+	for index, group := range groups {
 		groupDTOs, err := c.groupSlotsEntitiesToDTOs(group.TeamSlots())
 		if err != nil {
 			return nil, fmt.Errorf("converting slots error: %w", err)
 		}
 
-		dtoTeams[index] = dto.Group{
+		dtoTeams[index] = GroupDTO{
 			Name:      group.Name(),
+			Size:      group.Size(),
 			TeamSlots: groupDTOs,
+			Matchdays: matchdayEntitiesToDTOs(group.Matchdays(), groupDTOs),
+			Matches:   matchEntitiesToDTOs(group.Matches()),
+			Standings: standingsToDTOs(standings[index], groupDTOs),
 		}
 	}
 
@@ -83,9 +152,9 @@ func (c Converter) groupSlotsEntitiesToDTOs(entitySlots []*entity.GroupSlot) ([]
 	return dtoTeams, nil
 }
 
-func fetchTeamsIDFromTourneysCollection(tourneys []*entity.Tourney) (ids []string) {
-	for _, tourney := range tourneys {
-		for _, group := range tourney.Groups() {
+func fetchTeamsIDFromTourneysCollection(overviews []*service.TourneyOverview) (ids []string) {
+	for _, overview := range overviews {
+		for _, group := range overview.Tourney.Groups() {
 			for _, slot := range group.TeamSlots() {
 				ids = append(ids, slot.TeamID())
 			}
@@ -98,7 +167,7 @@ func fetchTeamsIDFromTourneysCollection(tourneys []*entity.Tourney) (ids []strin
 func (c *Converter) fillTeamsStorage(ids []string) error {
 	teams, err := c.teams.TeamsByID(ids)
 	if err != nil {
-		return fmt.Errorf("getting teams by ID error: %w", err)
+		return fmt.Errorf("getting teams by ID error: %w", &service.TeamsServiceError{Err: err})
 	}
 
 	c.teamsStorage = make(map[string]dto.Team, len(teams))
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -18,56 +19,133 @@ type UserParams struct {
 }
 
 type GenerateTourneyRequest struct {
-	GroupsCount   int          `json:"groups_count"`
-	TeamsPerGroup int          `json:"teams_per_group"`
-	Leagues       []string     `json:"leagues"`
-	Users         []UserParams `json:"users"`
+	GroupsCount      int      `json:"groups_count"`
+	TeamsPerGroup    int      `json:"teams_per_group"`
+	TeamsCount       int      `json:"teams_count,omitempty"`
+	Leagues          []string `json:"leagues"`
+	MinRating        float64  `json:"min_rating,omitempty"`
+	MaxRating        float64  `json:"max_rating,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+	DrawID           string   `json:"draw_id,omitempty"`
+	DrawMode         string   `json:"draw_mode,omitempty"`
+	LeagueProtection string   `json:"league_protection,omitempty"`
+	// UserSpread is on unless explicitly disabled.
+	UserSpread           *bool      `json:"user_spread,omitempty"`
+	MaxUserSlotsPerGroup int        `json:"max_user_slots_per_group,omitempty"`
+	AssignmentMode       string     `json:"assignment_mode,omitempty"`
+	Candidates           int        `json:"candidates,omitempty"`
+	Top                  int        `json:"top,omitempty"`
+	Objective            string     `json:"objective,omitempty"`
+	RebalanceIterations  int        `json:"rebalance_iterations,omitempty"`
+	Constraints          []string   `json:"constraints,omitempty"`
+	Rivals               [][]string `json:"rivals,omitempty"`
+	AllowLowerRatings    bool       `json:"allow_lower_ratings,omitempty"`
+	RequiredTeamsPolicy  string     `json:"required_teams_policy,omitempty"`
+	SubstituteUnknown    bool       `json:"substitute_unknown_teams,omitempty"`
+	GroupNaming          string     `json:"group_naming,omitempty"`
+	GroupNames           []string   `json:"group_names,omitempty"`
+	DoubleRoundRobin     bool       `json:"double_round_robin,omitempty"`
+	KnockoutQualifiers   int        `json:"knockout_qualifiers,omitempty"`
+	ThirdPlaceMatch      bool       `json:"third_place_match,omitempty"`
+	// Tiebreakers rank the group standings in order, a given list must start with `points`
+	// or it's rejected under the tiebreakers param.
+	Tiebreakers []string     `json:"tiebreakers,omitempty"`
+	Users       []UserParams `json:"users"`
 }
 
 type HTTPServer struct {
 	tourneyGenerator *service.TourneyGenerator
+	tourneyCatalog   *service.TourneyCatalog
 	dtoConverter     *converter.Converter
+	fairDraw         *service.FairDraw
 }
 
-func NewHTTPServer(tourneyGenerator *service.TourneyGenerator, dtoConverter *converter.Converter) *HTTPServer {
-	return &HTTPServer{tourneyGenerator: tourneyGenerator, dtoConverter: dtoConverter}
+func NewHTTPServer(
+	tourneyGenerator *service.TourneyGenerator,
+	tourneyCatalog *service.TourneyCatalog,
+	dtoConverter *converter.Converter,
+	fairDraw *service.FairDraw,
+) *HTTPServer {
+	return &HTTPServer{
+		tourneyGenerator: tourneyGenerator,
+		tourneyCatalog:   tourneyCatalog,
+		dtoConverter:     dtoConverter,
+		fairDraw:         fairDraw,
+	}
 }
 
 func (s HTTPServer) GenerateTourney(writer http.ResponseWriter, request *http.Request) {
 	tourneyRequest := new(GenerateTourneyRequest)
 	if err := json.NewDecoder(request.Body).Decode(&tourneyRequest); err != nil {
-		http.Error(writer, "bad tourney request payload", http.StatusBadRequest)
+		writeProblem(writer, Problem{
+			Type:   problemTypeInvalidRequest,
+			Title:  "Bad tourney request payload",
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		})
 		log.Printf("tourney request payload error: %v\n", err)
 
 		return
 	}
 
-	usersSettings := make([]*service.UserSettingsDTO, len(tourneyRequest.Users))
-	for i, userParams := range tourneyRequest.Users {
-		usersSettings[i] = service.NewUserSettingsDTO(userParams.UserID, userParams.TeamsCount, userParams.RequiredTeams)
+	var draw *entity.DrawCommitment
+
+	if tourneyRequest.DrawID != "" {
+		if err := tourneyRequest.validateFairDraw(""); err != nil {
+			writeProblem(writer, problemFromError(err, true))
+
+			return
+		}
+
+		var (
+			seed int64
+			err  error
+		)
+
+		if draw, seed, err = s.fairDraw.Claim(tourneyRequest.DrawID); err != nil {
+			writeProblem(writer, fairDrawProblem(err))
+			log.Printf("fair draw claim error: %v\n", err)
+
+			return
+		}
+
+		tourneyRequest.Seed = &seed
 	}
 
-	tourney, err := s.tourneyGenerator.Generate(
-		tourneyRequest.GroupsCount,
-		tourneyRequest.TeamsPerGroup,
-		tourneyRequest.Leagues,
-		usersSettings,
-	)
+	overviews, err := s.generateTourneys(tourneyRequest, true)
 	if err != nil {
-		http.Error(writer, "tourney generation error", http.StatusBadRequest)
+		s.releaseDraw(draw)
+		writeProblem(writer, problemFromError(err, true))
 		log.Printf("tourney generation error: %v\n", err)
 
 		return
 	}
 
-	tourneyDTOs, err := s.dtoConverter.TourneyEntitiesToDTOs([]*entity.Tourney{tourney})
+	tourneyDTOs, err := s.dtoConverter.TourneyOverviewsToDTOs(overviews)
 	if err != nil {
-		writer.WriteHeader(http.StatusInternalServerError)
+		s.releaseDraw(draw)
+		writeProblem(writer, problemFromError(err, false))
 		log.Printf("converting tourney entity to DTO error: %v\n", err)
 
 		return
 	}
 
+	// the tourneys are stored once nothing else can fail, so no tourney is stored without being returned.
+	if err = s.tourneyGenerator.Store(overviews); err != nil {
+		s.releaseDraw(draw)
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("storing tourneys error: %v\n", err)
+
+		return
+	}
+
+	if draw != nil {
+		drawDTO := converter.DrawCommitmentEntityToDTO(draw)
+		for i := range tourneyDTOs {
+			tourneyDTOs[i].Draw = &drawDTO
+		}
+	}
+
 	err = json.NewEncoder(writer).Encode(tourneyDTOs)
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -79,9 +157,71 @@ func (s HTTPServer) GenerateTourney(writer http.ResponseWriter, request *http.Re
 	writer.WriteHeader(http.StatusOK)
 }
 
+// releaseDraw reopens the claimed draw of a request which failed before anything of the draw was shown.
+func (s HTTPServer) releaseDraw(draw *entity.DrawCommitment) {
+	if draw == nil {
+		return
+	}
+
+	if err := s.fairDraw.Release(draw.ID()); err != nil {
+		log.Printf("releasing draw `%s` error: %v\n", draw.ID(), err)
+	}
+}
+
+// generateTourneys draws the tourneys of the request, gives them IDs to be stored when persist is set.
+func (s HTTPServer) generateTourneys(tourneyRequest *GenerateTourneyRequest, persist bool) ([]*service.TourneyOverview, error) {
+	if err := tourneyRequest.validate(); err != nil {
+		return nil, err
+	}
+
+	usersSettings := make([]*service.UserSettingsDTO, len(tourneyRequest.Users))
+	for i, userParams := range tourneyRequest.Users {
+		usersSettings[i] = service.NewUserSettingsDTO(userParams.UserID, userParams.TeamsCount, userParams.RequiredTeams)
+	}
+
+	tourneySettings := service.
+		NewTourneySettingsDTO(tourneyRequest.GroupsCount, tourneyRequest.TeamsPerGroup, tourneyRequest.Leagues).
+		WithRatingRange(tourneyRequest.MinRating, tourneyRequest.MaxRating).
+		WithLowerRatingsFallback(tourneyRequest.AllowLowerRatings).
+		WithRequiredTeamsPolicy(tourneyRequest.RequiredTeamsPolicy).
+		WithUnknownTeamsSubstitution(tourneyRequest.SubstituteUnknown).
+		WithGroupNames(tourneyRequest.GroupNaming, tourneyRequest.GroupNames).
+		WithTeamsCount(tourneyRequest.TeamsCount).
+		WithDoubleRoundRobin(tourneyRequest.DoubleRoundRobin).
+		WithKnockout(tourneyRequest.KnockoutQualifiers, tourneyRequest.ThirdPlaceMatch).
+		WithTiebreakers(tourneyRequest.Tiebreakers).
+		WithSeed(tourneyRequest.Seed).
+		WithDrawMode(tourneyRequest.DrawMode).
+		WithLeagueProtection(tourneyRequest.LeagueProtection).
+		WithUserSpread(tourneyRequest.UserSpread == nil || *tourneyRequest.UserSpread, tourneyRequest.MaxUserSlotsPerGroup).
+		WithAssignmentMode(tourneyRequest.AssignmentMode).
+		WithCandidates(tourneyRequest.Candidates, tourneyRequest.Top, tourneyRequest.Objective).
+		WithRebalance(tourneyRequest.RebalanceIterations).
+		WithConstraints(tourneyRequest.Constraints, tourneyRequest.Rivals)
+
+	generate := s.tourneyGenerator.Draw
+	if persist {
+		generate = s.tourneyGenerator.Generate
+	}
+
+	overviews, err := generate(tourneySettings, usersSettings)
+	if err != nil {
+		return nil, fmt.Errorf("generating tourney error: %w", err)
+	}
+
+	return overviews, nil
+}
+
 func ConfigureRouter(server *HTTPServer) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/tourneys", server.GenerateTourney).Methods(http.MethodPost)
+	router.HandleFunc("/tourneys", server.ListTourneys).Methods(http.MethodGet)
+	router.HandleFunc("/tourneys/{id}", server.GetTourney).Methods(http.MethodGet)
+	router.HandleFunc("/tourneys/{id}", server.DeleteTourney).Methods(http.MethodDelete)
+	router.HandleFunc("/tourneys/{id}/results", server.SubmitResults).Methods(http.MethodPost)
+	router.HandleFunc("/tourneys/verification", server.VerifyTourney).Methods(http.MethodPost)
+	router.HandleFunc("/draws", server.CommitDraw).Methods(http.MethodPost)
+	router.HandleFunc("/draws/{id}/client-seeds", server.AddClientSeed).Methods(http.MethodPost)
 
 	return router
 }
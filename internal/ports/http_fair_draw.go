@@ -0,0 +1,158 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
+	"github.com/twizar/tourneys/internal/ports/converter"
+)
+
+type ClientSeedRequest struct {
+	UserID string `json:"user_id"`
+	Seed   string `json:"seed"`
+}
+
+type VerifyTourneyRequest struct {
+	Tourney GenerateTourneyRequest `json:"tourney"`
+	Draw    converter.DrawDTO      `json:"draw"`
+}
+
+func (s HTTPServer) CommitDraw(writer http.ResponseWriter, _ *http.Request) {
+	draw, err := s.fairDraw.Commit()
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("committing draw error: %v\n", err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(writer).Encode(converter.DrawCommitmentEntityToDTO(draw)); err != nil {
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
+
+func (s HTTPServer) AddClientSeed(writer http.ResponseWriter, request *http.Request) {
+	clientSeedRequest := new(ClientSeedRequest)
+	if err := json.NewDecoder(request.Body).Decode(&clientSeedRequest); err != nil {
+		writeProblem(writer, Problem{
+			Type:   problemTypeInvalidRequest,
+			Title:  "Bad client seed request payload",
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		})
+		log.Printf("client seed request payload error: %v\n", err)
+
+		return
+	}
+
+	draw, err := s.fairDraw.AddClientSeed(mux.Vars(request)["id"], clientSeedRequest.UserID, clientSeedRequest.Seed)
+	if err != nil {
+		writeProblem(writer, fairDrawProblem(err))
+		log.Printf("adding client seed error: %v\n", err)
+
+		return
+	}
+
+	if err = json.NewEncoder(writer).Encode(converter.DrawCommitmentEntityToDTO(draw)); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
+
+func (s HTTPServer) VerifyTourney(writer http.ResponseWriter, request *http.Request) {
+	verifyRequest := new(VerifyTourneyRequest)
+	if err := json.NewDecoder(request.Body).Decode(&verifyRequest); err != nil {
+		writeProblem(writer, Problem{
+			Type:   problemTypeInvalidRequest,
+			Title:  "Bad verification request payload",
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		})
+		log.Printf("verification request payload error: %v\n", err)
+
+		return
+	}
+
+	if err := verifyRequest.Tourney.validateFairDraw("tourney."); err != nil {
+		writeProblem(writer, problemFromError(err, true))
+
+		return
+	}
+
+	clientSeeds := make([]*entity.ClientSeed, len(verifyRequest.Draw.ClientSeeds))
+	for i, clientSeed := range verifyRequest.Draw.ClientSeeds {
+		clientSeeds[i] = entity.NewClientSeed(clientSeed.UserID, clientSeed.Seed)
+	}
+
+	seed, err := service.VerifySeed(verifyRequest.Draw.Commitment, verifyRequest.Draw.ServerSeed, clientSeeds)
+	if err != nil {
+		writeProblem(writer, fairDrawProblem(err))
+		log.Printf("verifying seed error: %v\n", err)
+
+		return
+	}
+
+	verifyRequest.Tourney.Seed = &seed
+
+	overviews, err := s.generateTourneys(&verifyRequest.Tourney, false)
+	if err != nil {
+		writeProblem(writer, problemFromError(err, true))
+		log.Printf("tourney generation error: %v\n", err)
+
+		return
+	}
+
+	tourneyDTOs, err := s.dtoConverter.TourneyOverviewsToDTOs(overviews)
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("converting tourney entity to DTO error: %v\n", err)
+
+		return
+	}
+
+	for i := range tourneyDTOs {
+		tourneyDTOs[i].Draw = &verifyRequest.Draw
+	}
+
+	if err = json.NewEncoder(writer).Encode(tourneyDTOs); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
+
+// fairDrawProblem answers with the cause of a fair draw failure rather than the whole error chain,
+// the storage failures are answered as by any other request.
+func fairDrawProblem(err error) Problem {
+	causes := []struct {
+		err    error
+		status int
+	}{
+		{err: repository.ErrDrawNotFound, status: http.StatusNotFound},
+		{err: entity.ErrDrawRevealed, status: http.StatusConflict},
+		{err: entity.ErrClientSeedExists, status: http.StatusConflict},
+		{err: repository.ErrVersionConflict, status: http.StatusConflict},
+		{err: entity.ErrClientSeedInvalid, status: http.StatusBadRequest},
+		{err: service.ErrCommitmentMismatch, status: http.StatusUnprocessableEntity},
+	}
+
+	for _, cause := range causes {
+		if errors.Is(err, cause.err) {
+			return Problem{
+				Type:   problemTypeFairDraw,
+				Title:  "Fair draw error",
+				Status: cause.status,
+				Detail: cause.err.Error(),
+			}
+		}
+	}
+
+	return problemFromError(err, false)
+}
@@ -0,0 +1,220 @@
+package ports_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/common/test/mock"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/ports"
+	"github.com/twizar/tourneys/internal/ports/converter"
+)
+
+func TestHTTPServer_FairDraw(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	teamsSearch := readTeams(t, "../../test/data/search_teams_payload.js")
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).AnyTimes().Return(teamsSearch, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	router := newRouter(teamsService, service.TimeSeedSource{})
+
+	writer := postJSON(t, router, "/draws", nil)
+	require.Equal(t, http.StatusCreated, writer.Code)
+
+	var commitment converter.DrawDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&commitment))
+	require.NotEmpty(t, commitment.Commitment)
+	assert.Empty(t, commitment.ServerSeed)
+
+	for _, clientSeed := range []ports.ClientSeedRequest{{UserID: user1ID, Seed: "lucky"}, {UserID: user2ID, Seed: "seven"}} {
+		writer = postJSON(t, router, "/draws/"+commitment.ID+"/client-seeds", clientSeed)
+		require.Equal(t, http.StatusOK, writer.Code)
+	}
+
+	writer = postJSON(t, router, "/draws/"+commitment.ID+"/client-seeds", ports.ClientSeedRequest{UserID: user1ID, Seed: "again"})
+	assert.Equal(t, http.StatusConflict, writer.Code)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		DrawID:        commitment.ID,
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 4},
+			{UserID: user2ID, TeamsCount: 4},
+		},
+	}
+
+	// a request failing before the draw leaves the draw open.
+	invalidRequest := tourneyRequest
+	invalidRequest.GroupsCount = 0
+	writer = postJSON(t, router, "/tourneys", invalidRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	// a fair draw makes a single tourney, nobody picks among candidates after the reveal.
+	candidatesRequest := tourneyRequest
+	candidatesRequest.Candidates, candidatesRequest.Top = 5, 5
+	writer = postJSON(t, router, "/tourneys", candidatesRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	require.Len(t, problem.InvalidParams, 2)
+	assert.Equal(t, "candidates", problem.InvalidParams[0].Name)
+	assert.Equal(t, "top", problem.InvalidParams[1].Name)
+
+	// concurrent requests for the same draw get one tourney.
+	writers := make([]*httptest.ResponseRecorder, 5)
+
+	var wg sync.WaitGroup
+
+	for i := range writers {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			writers[i] = postJSON(t, router, "/tourneys", tourneyRequest)
+		}(i)
+	}
+
+	wg.Wait()
+
+	codes := make(map[int]int)
+
+	for _, concurrentWriter := range writers {
+		codes[concurrentWriter.Code]++
+
+		if concurrentWriter.Code == http.StatusOK {
+			writer = concurrentWriter
+		}
+	}
+
+	require.Equal(t, map[int]int{http.StatusOK: 1, http.StatusConflict: len(writers) - 1}, codes)
+
+	var drawn []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&drawn))
+	require.Len(t, drawn, 1)
+	require.NotNil(t, drawn[0].Draw)
+	assert.Equal(t, commitment.Commitment, drawn[0].Draw.Commitment)
+	assert.Equal(t, commitment.Commitment, service.Commitment(drawn[0].Draw.ServerSeed))
+	assert.Len(t, drawn[0].Draw.ClientSeeds, 2)
+
+	writer = postJSON(t, router, "/tourneys", tourneyRequest)
+	assert.Equal(t, http.StatusConflict, writer.Code)
+
+	tourneyRequest.DrawID = ""
+	verifyRequest := ports.VerifyTourneyRequest{Tourney: tourneyRequest, Draw: *drawn[0].Draw}
+
+	writer = postJSON(t, router, "/tourneys/verification", verifyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var verified []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&verified))
+	assert.Equal(t, withoutIdentity(drawn), verified)
+
+	candidatesVerifyRequest := verifyRequest
+	candidatesVerifyRequest.Tourney.Candidates = 3
+	writer = postJSON(t, router, "/tourneys/verification", candidatesVerifyRequest)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	verifyRequest.Draw.ServerSeed = "tampered"
+	writer = postJSON(t, router, "/tourneys/verification", verifyRequest)
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+}
+
+func TestHTTPServer_FairDrawAcrossInstances(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	teamsSearch := readTeams(t, "../../test/data/search_teams_payload.js")
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).AnyTimes().Return(teamsSearch, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	// the instances share the storage only, as Lambda instances do.
+	tourneys, draws := adapters.NewMemoryTourneyRepository(), adapters.NewMemoryDrawRepository()
+	routers := []*mux.Router{
+		newRouterWithRepositories(teamsService, service.TimeSeedSource{}, tourneys, draws),
+		newRouterWithRepositories(teamsService, service.TimeSeedSource{}, tourneys, draws),
+	}
+
+	writer := postJSON(t, routers[0], "/draws", nil)
+	require.Equal(t, http.StatusCreated, writer.Code)
+
+	var commitment converter.DrawDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&commitment))
+
+	writer = postJSON(t, routers[1], "/draws/"+commitment.ID+"/client-seeds", ports.ClientSeedRequest{UserID: user1ID, Seed: "lucky"})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	writer = postJSON(t, routers[0], "/draws/"+commitment.ID+"/client-seeds", ports.ClientSeedRequest{UserID: user1ID, Seed: "again"})
+	require.Equal(t, http.StatusConflict, writer.Code)
+	assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	assert.Equal(t, "/problems/fair-draw", problem.Type)
+	assert.Equal(t, entity.ErrClientSeedExists.Error(), problem.Detail)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		DrawID:        commitment.ID,
+		Users:         []ports.UserParams{{UserID: user1ID, TeamsCount: 4}, {UserID: user2ID, TeamsCount: 4}},
+	}
+
+	writer = postJSON(t, routers[1], "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	writer = postJSON(t, routers[0], "/tourneys", tourneyRequest)
+	assert.Equal(t, http.StatusConflict, writer.Code)
+
+	writer = postJSON(t, routers[0], "/draws/unknown/client-seeds", ports.ClientSeedRequest{UserID: user2ID, Seed: "seven"})
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+}
+
+func TestHTTPServer_FairDrawBadPayloads(t *testing.T) {
+	t.Parallel()
+
+	router := newRouter(mock.NewMockTeams(gomock.NewController(t)), service.TimeSeedSource{})
+
+	for _, path := range []string{"/draws/any/client-seeds", "/tourneys/verification"} {
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, path, strings.NewReader("{")))
+
+		require.Equal(t, http.StatusBadRequest, writer.Code, path)
+		assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"), path)
+
+		var problem ports.Problem
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem), path)
+		assert.Equal(t, "/problems/invalid-request", problem.Type, path)
+	}
+}
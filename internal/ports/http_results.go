@@ -0,0 +1,69 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/twizar/tourneys/internal/application/service"
+)
+
+type MatchResultParams struct {
+	Group              string `json:"group"`
+	HomeSlot           int    `json:"home_slot"`
+	AwaySlot           int    `json:"away_slot"`
+	HomeGoals          int    `json:"home_goals"`
+	AwayGoals          int    `json:"away_goals"`
+	HomeFairPlayPoints int    `json:"home_fair_play_points,omitempty"`
+	AwayFairPlayPoints int    `json:"away_fair_play_points,omitempty"`
+}
+
+// SubmitResultsRequest carries the results of the fixtures of a stored tourney.
+type SubmitResultsRequest struct {
+	Results []MatchResultParams `json:"results"`
+}
+
+// SubmitResults records the results of the stored tourney and returns the tourney with the new standings.
+func (s HTTPServer) SubmitResults(writer http.ResponseWriter, request *http.Request) {
+	resultsRequest := new(SubmitResultsRequest)
+	if err := json.NewDecoder(request.Body).Decode(&resultsRequest); err != nil {
+		writeProblem(writer, Problem{
+			Type:   problemTypeInvalidRequest,
+			Title:  "Bad results request payload",
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		})
+		log.Printf("results request payload error: %v\n", err)
+
+		return
+	}
+
+	results := make([]*service.MatchResultDTO, len(resultsRequest.Results))
+	for i, result := range resultsRequest.Results {
+		results[i] = service.
+			NewMatchResultDTO(result.Group, result.HomeSlot, result.AwaySlot, result.HomeGoals, result.AwayGoals).
+			WithFairPlayPoints(result.HomeFairPlayPoints, result.AwayFairPlayPoints)
+	}
+
+	overview, err := s.tourneyCatalog.RecordResults(mux.Vars(request)["id"], results)
+	if err != nil {
+		writeProblem(writer, problemFromError(err, true))
+		log.Printf("recording results error: %v\n", err)
+
+		return
+	}
+
+	tourneyDTOs, err := s.dtoConverter.TourneyOverviewsToDTOs([]*service.TourneyOverview{overview})
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("converting tourney entity to DTO error: %v\n", err)
+
+		return
+	}
+
+	if err = json.NewEncoder(writer).Encode(tourneyDTOs[0]); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
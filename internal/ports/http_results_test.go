@@ -0,0 +1,381 @@
+package ports_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/ports"
+	"github.com/twizar/tourneys/internal/ports/converter"
+)
+
+func TestHTTPServer_SubmitResults(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouter(teamsService, service.TimeSeedSource{})
+
+	seed := int64(42)
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	writer := postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var generated []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&generated))
+	require.Len(t, generated, 1)
+
+	group := generated[0].Groups[0]
+	for i, standing := range group.Standings {
+		expected := converter.StandingDTO{Position: i + 1, Slot: i, Team: group.TeamSlots[i]}
+		if i > 0 {
+			expected.Explanation = "level on every tiebreaker, ranked by the draw order"
+		}
+
+		assert.Equal(t, expected, standing)
+	}
+
+	var results []ports.MatchResultParams
+
+	for i, matchday := range group.Matchdays[:2] {
+		for j, fixture := range matchday.Fixtures {
+			results = append(results, ports.MatchResultParams{
+				Group:     group.Name,
+				HomeSlot:  fixture.HomeSlot,
+				AwaySlot:  fixture.AwaySlot,
+				HomeGoals: i + j + 1,
+				AwayGoals: 1,
+			})
+		}
+	}
+
+	resultsPath := "/tourneys/" + generated[0].ID + "/results"
+
+	writer = postJSON(t, router, resultsPath, ports.SubmitResultsRequest{Results: results})
+	require.Equal(t, http.StatusOK, writer.Code, writer.Body.String())
+
+	var tourney converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&tourney))
+
+	writer = serve(t, router, http.MethodGet, "/tourneys/"+generated[0].ID)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var stored converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&stored))
+	assert.Equal(t, tourney.Groups, stored.Groups)
+
+	assert.Equal(t, generated[0].Groups[0].TeamSlots, tourney.Groups[0].TeamSlots)
+	require.Len(t, tourney.Groups[0].Matches, len(results))
+	assert.Empty(t, tourney.Groups[1].Matches)
+
+	expected := make(map[int]converter.StandingDTO)
+
+	for _, result := range results {
+		for _, side := range []struct{ slot, goalsFor, goalsAgainst, awayGoals int }{
+			{slot: result.HomeSlot, goalsFor: result.HomeGoals, goalsAgainst: result.AwayGoals},
+			{slot: result.AwaySlot, goalsFor: result.AwayGoals, goalsAgainst: result.HomeGoals, awayGoals: result.AwayGoals},
+		} {
+			row := expected[side.slot]
+			row.Played++
+			row.AwayGoals += side.awayGoals
+			row.GoalsFor += side.goalsFor
+			row.GoalsAgainst += side.goalsAgainst
+			row.GoalDifference = row.GoalsFor - row.GoalsAgainst
+
+			switch {
+			case side.goalsFor > side.goalsAgainst:
+				row.Won++
+				row.Points += 3
+			case side.goalsFor == side.goalsAgainst:
+				row.Drawn++
+				row.Points++
+			default:
+				row.Lost++
+			}
+
+			expected[side.slot] = row
+		}
+	}
+
+	standings := tourney.Groups[0].Standings
+	require.Len(t, standings, 4)
+
+	for i, standing := range standings {
+		assert.Equal(t, i+1, standing.Position)
+		assert.Equal(t, tourney.Groups[0].TeamSlots[standing.Slot], standing.Team)
+
+		row := expected[standing.Slot]
+		row.Position, row.Slot, row.Team = standing.Position, standing.Slot, standing.Team
+		row.SeparatedBy, row.Explanation = standing.SeparatedBy, standing.Explanation
+		assert.Equal(t, row, standing)
+
+		if i > 0 {
+			assert.GreaterOrEqual(t, standings[i-1].Points, standing.Points)
+		}
+	}
+
+	unplayedFixture := group.Matchdays[2].Fixtures[0]
+	unplayed := ports.MatchResultParams{Group: group.Name, HomeSlot: unplayedFixture.HomeSlot, AwaySlot: unplayedFixture.AwaySlot}
+	reversed := ports.MatchResultParams{Group: group.Name, HomeSlot: unplayed.AwaySlot, AwaySlot: unplayed.HomeSlot}
+	negative := unplayed
+	negative.HomeGoals = -1
+
+	for name, invalid := range map[string][]ports.MatchResultParams{
+		"unknown group":  {{Group: "Z", AwaySlot: 1}},
+		"not scheduled":  {reversed},
+		"already played": {results[0]},
+		"played twice":   {unplayed, unplayed},
+		"negative score": {negative},
+	} {
+		writer = postJSON(t, router, resultsPath, ports.SubmitResultsRequest{Results: invalid})
+		assert.Equal(t, http.StatusBadRequest, writer.Code, name)
+	}
+
+	// the rejected results leave the stored tourney as it was.
+	writer = serve(t, router, http.MethodGet, "/tourneys/"+generated[0].ID)
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&stored))
+	assert.Equal(t, tourney.Groups, stored.Groups)
+
+	writer = postJSON(t, router, "/tourneys/unknown/results", ports.SubmitResultsRequest{Results: results})
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+func TestHTTPServer_SubmitResults_Tiebreakers(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouter(teamsService, service.TimeSeedSource{})
+
+	seed := int64(7)
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   1,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	writer := postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var generated []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&generated))
+
+	group := generated[0].Groups[0]
+
+	// scores are given as slot a vs slot b, the fixture decides who is at home.
+	type score struct{ a, b, goalsA, goalsB int }
+
+	resultsOf := func(scores []score, fairPlayPoints map[int]int) []ports.MatchResultParams {
+		results := make([]ports.MatchResultParams, 0, len(scores))
+
+		for _, score := range scores {
+			for _, matchday := range group.Matchdays {
+				for _, fixture := range matchday.Fixtures {
+					result := ports.MatchResultParams{Group: group.Name, HomeSlot: fixture.HomeSlot, AwaySlot: fixture.AwaySlot}
+
+					switch {
+					case fixture.HomeSlot == score.a && fixture.AwaySlot == score.b:
+						result.HomeGoals, result.AwayGoals = score.goalsA, score.goalsB
+					case fixture.HomeSlot == score.b && fixture.AwaySlot == score.a:
+						result.HomeGoals, result.AwayGoals = score.goalsB, score.goalsA
+					default:
+						continue
+					}
+
+					result.HomeFairPlayPoints = fairPlayPoints[fixture.HomeSlot]
+					result.AwayFairPlayPoints = fairPlayPoints[fixture.AwaySlot]
+					results = append(results, result)
+				}
+			}
+		}
+
+		require.Len(t, results, len(scores))
+
+		return results
+	}
+
+	// every standings are of a new tourney, the same seed draws the same groups whatever the tiebreakers.
+	standingsOf := func(tiebreakers []string, results []ports.MatchResultParams) []converter.StandingDTO {
+		request := tourneyRequest
+		request.Tiebreakers = tiebreakers
+
+		writer := postJSON(t, router, "/tourneys", request)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var drawn []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&drawn))
+		require.Equal(t, group.TeamSlots, drawn[0].Groups[0].TeamSlots)
+
+		writer = postJSON(t, router, "/tourneys/"+drawn[0].ID+"/results", ports.SubmitResultsRequest{Results: results})
+		require.Equal(t, http.StatusOK, writer.Code, writer.Body.String())
+
+		var tourney converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&tourney))
+
+		return tourney.Groups[0].Standings
+	}
+
+	slotsOf := func(standings []converter.StandingDTO) []int {
+		slots := make([]int, len(standings))
+		for i, standing := range standings {
+			slots[i] = standing.Slot
+		}
+
+		return slots
+	}
+
+	// slots 0, 1 and 2 beat each other in a circle and all beat slot 3.
+	cycle := []score{{0, 1, 1, 0}, {1, 2, 1, 0}, {2, 0, 1, 0}, {0, 3, 3, 0}, {1, 3, 1, 0}, {2, 3, 2, 0}}
+
+	standings := standingsOf([]string{service.TiebreakerPoints, service.TiebreakerGoalDifference}, resultsOf(cycle, nil))
+	assert.Equal(t, []int{0, 2, 1, 3}, slotsOf(standings))
+	assert.Empty(t, standings[0].SeparatedBy)
+	assert.Equal(t, service.TiebreakerGoalDifference, standings[1].SeparatedBy)
+	assert.Equal(t, "goal difference 3 vs 2", standings[1].Explanation)
+	assert.Equal(t, "goal difference 2 vs 1", standings[2].Explanation)
+	assert.Empty(t, standings[3].SeparatedBy)
+	assert.Empty(t, standings[3].Explanation)
+
+	standings = standingsOf([]string{service.TiebreakerPoints, service.TiebreakerFairPlay},
+		resultsOf(cycle, map[int]int{1: 5, 2: 2}))
+	assert.Equal(t, []int{0, 2, 1, 3}, slotsOf(standings))
+	assert.Equal(t, service.TiebreakerFairPlay, standings[1].SeparatedBy)
+	assert.Equal(t, "fair play penalty points 0 vs 6", standings[1].Explanation)
+	assert.Equal(t, "fair play penalty points 6 vs 15", standings[2].Explanation)
+
+	// the circle isn't broken head-to-head, so the lots decide.
+	lotsTiebreakers := []string{
+		service.TiebreakerPoints, service.TiebreakerHeadToHeadPoints, service.TiebreakerHeadToHeadGoalDifference, service.TiebreakerLots,
+	}
+
+	standings = standingsOf(lotsTiebreakers, resultsOf(cycle, nil))
+	assert.ElementsMatch(t, []int{0, 1, 2}, slotsOf(standings)[:3])
+	assert.Equal(t, 3, standings[3].Slot)
+	assert.Equal(t, service.TiebreakerLots, standings[1].SeparatedBy)
+	assert.Equal(t, service.TiebreakerLots, standings[2].SeparatedBy)
+	assert.Equal(t, slotsOf(standings), slotsOf(standingsOf(lotsTiebreakers, resultsOf(cycle, nil))))
+
+	// slot 3 beats slot 1, so the pairs level on points are separated head-to-head against the goal difference.
+	headToHead := append(append([]score(nil), cycle[:4]...), score{1, 3, 0, 1}, cycle[5])
+
+	standings = standingsOf([]string{service.TiebreakerPoints, service.TiebreakerHeadToHeadPoints}, resultsOf(headToHead, nil))
+	assert.Equal(t, []int{2, 0, 3, 1}, slotsOf(standings))
+	assert.Equal(t, service.TiebreakerHeadToHeadPoints, standings[1].SeparatedBy)
+	assert.Equal(t, "head-to-head points 3 vs 0", standings[1].Explanation)
+	assert.Empty(t, standings[2].SeparatedBy)
+	assert.Equal(t, "head-to-head points 3 vs 0", standings[3].Explanation)
+
+	for _, invalid := range []struct {
+		tiebreakers []string
+		param       ports.InvalidParam
+	}{
+		{
+			tiebreakers: []string{service.TiebreakerPoints, "coin_toss"},
+			param:       ports.InvalidParam{Name: "tiebreakers[1]", Reason: "`coin_toss` is unknown"},
+		},
+		{
+			tiebreakers: []string{service.TiebreakerPoints, service.TiebreakerPoints},
+			param:       ports.InvalidParam{Name: "tiebreakers[1]", Reason: "`points` is repeated"},
+		},
+		{
+			tiebreakers: []string{service.TiebreakerGoalDifference, service.TiebreakerPoints},
+			param:       ports.InvalidParam{Name: "tiebreakers", Reason: "must start with `points`"},
+		},
+	} {
+		request := tourneyRequest
+		request.Tiebreakers = invalid.tiebreakers
+
+		writer = postJSON(t, router, "/tourneys", request)
+		require.Equal(t, http.StatusBadRequest, writer.Code, invalid.tiebreakers)
+
+		var problem ports.Problem
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+		assert.Equal(t, []ports.InvalidParam{invalid.param}, problem.InvalidParams, invalid.tiebreakers)
+	}
+}
+
+func TestHTTPServer_SubmitResults_Conflict(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+	tourneys := &interleavingTourneyRepository{MemoryTourneyRepository: adapters.NewMemoryTourneyRepository()}
+
+	router := newRouterWithRepository(teamsService, service.TimeSeedSource{}, tourneys)
+
+	seed := int64(42)
+	writer := postJSON(t, router, "/tourneys", ports.GenerateTourneyRequest{
+		GroupsCount:   1,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var generated []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&generated))
+
+	group := generated[0].Groups[0]
+	fixture := group.Matchdays[0].Fixtures[0]
+	results := ports.SubmitResultsRequest{Results: []ports.MatchResultParams{
+		{Group: group.Name, HomeSlot: fixture.HomeSlot, AwaySlot: fixture.AwaySlot, HomeGoals: 1},
+	}}
+	resultsPath := "/tourneys/" + generated[0].ID + "/results"
+
+	// another request records results between the read and the save.
+	tourneys.meanwhile = func(id string) {
+		tourney, err := tourneys.MemoryTourneyRepository.Get(id)
+		require.NoError(t, err)
+		require.NoError(t, tourneys.MemoryTourneyRepository.Save(tourney))
+	}
+
+	writer = postJSON(t, router, resultsPath, results)
+	require.Equal(t, http.StatusConflict, writer.Code, writer.Body.String())
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	assert.Equal(t, "/problems/conflict", problem.Type)
+
+	// another request deletes the tourney between the read and the save, the results don't bring it back.
+	tourneys.meanwhile = func(id string) {
+		require.NoError(t, tourneys.MemoryTourneyRepository.Delete(id))
+	}
+
+	writer = postJSON(t, router, resultsPath, results)
+	require.Equal(t, http.StatusConflict, writer.Code, writer.Body.String())
+
+	tourneys.meanwhile = nil
+
+	writer = serve(t, router, http.MethodGet, "/tourneys/"+generated[0].ID)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+}
+
+// interleavingTourneyRepository runs the meanwhile change after every read, as another request would.
+type interleavingTourneyRepository struct {
+	*adapters.MemoryTourneyRepository
+	meanwhile func(id string)
+}
+
+func (r *interleavingTourneyRepository) Get(id string) (*entity.Tourney, error) {
+	tourney, err := r.MemoryTourneyRepository.Get(id)
+	if err == nil && r.meanwhile != nil {
+		r.meanwhile(id)
+	}
+
+	return tourney, err
+}
@@ -4,18 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/twizar/common/pkg/dto"
 	"github.com/twizar/common/test/mock"
+	"github.com/twizar/tourneys/internal/adapters"
 	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/domain/repository"
 	"github.com/twizar/tourneys/internal/ports"
 	"github.com/twizar/tourneys/internal/ports/converter"
 )
@@ -177,9 +186,11 @@ func TestHTTPServer_GenerateTourney(t *testing.T) {
 			t.Parallel()
 
 			teamsService := testCase.teamsServiceMockFactory()
-			tourneyGenerator := service.NewTourneyGenerator(teamsService)
+			tourneys := adapters.NewMemoryTourneyRepository()
+			tourneyGenerator := service.NewTourneyGenerator(teamsService, service.TimeSeedSource{}, tourneys)
 			dtoConverter := converter.NewConverter(teamsService)
-			server := ports.NewHTTPServer(tourneyGenerator, dtoConverter)
+			fairDraw := service.NewFairDraw(adapters.NewMemoryDrawRepository())
+			server := ports.NewHTTPServer(tourneyGenerator, service.NewTourneyCatalog(teamsService, tourneys), dtoConverter, fairDraw)
 			router := ports.ConfigureRouter(server)
 
 			tourneyParamsJSON, err := json.Marshal(testCase.request)
@@ -198,7 +209,1580 @@ func TestHTTPServer_GenerateTourney(t *testing.T) {
 			var result []dto.Tourney
 			err = json.Unmarshal(body, &result)
 			require.NoError(t, err)
-			assert.NotEmpty(t, result)
+			require.NotEmpty(t, result)
+
+			slotsCountByUserID := make(map[string]int, len(testCase.request.Users))
+			for _, group := range result[0].Groups {
+				for _, slot := range group.TeamSlots {
+					slotsCountByUserID[slot.UserID]++
+				}
+			}
+
+			for _, user := range testCase.request.Users {
+				assert.Equal(t, user.TeamsCount, slotsCountByUserID[user.UserID])
+			}
+		})
+	}
+}
+
+func TestHTTPServer_GenerateTourney_TeamsCountMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	writer := postTourney(t, mock.NewMockTeams(ctrl), ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 6},
+			{UserID: user2ID, TeamsCount: 6},
+		},
+	})
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_RatingRange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	teamsSearch := readTeams(t, "../../test/data/search_teams_payload.js")
+	users := []ports.UserParams{
+		{UserID: user1ID, TeamsCount: 4},
+		{UserID: user2ID, TeamsCount: 4},
+		{UserID: user3ID, TeamsCount: 4},
+		{UserID: user4ID, TeamsCount: 4},
+	}
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(4), []string{}, "rating", 0).Return(teamsSearch, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(teamsSearch, nil)
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		MinRating:     4,
+		MaxRating:     4.5,
+		Users:         users,
+	})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.NotEmpty(t, result)
+
+	for _, group := range result[0].Groups {
+		for _, slot := range group.TeamSlots {
+			assert.GreaterOrEqual(t, slot.Team.Rating, float64(4))
+			assert.LessOrEqual(t, slot.Team.Rating, 4.5)
+		}
+	}
+
+	// a successful draw reports the pool of the range as well.
+	availableTeams := 0
+
+	for _, team := range teamsSearch {
+		if team.Rating >= 4 && team.Rating <= 4.5 {
+			availableTeams++
+		}
+	}
+
+	require.NotNil(t, result[0].Report)
+	assert.Equal(t, &converter.TeamsPoolDTO{MinRating: 4, MaxRating: 4.5, AvailableTeams: availableTeams}, result[0].Report.Pool)
+
+	teamsService.EXPECT().SearchTeams(float64(5), []string{}, "rating", 0).Return(teamsSearch, nil)
+
+	writer = postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		MinRating:     5,
+		Users:         users,
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+
+	writer = postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		MinRating:     4.5,
+		MaxRating:     4.25,
+		Users:         users,
+	})
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	// a max rating below the default min rating lowers the min rating instead of failing validation.
+	teamsService.EXPECT().SearchTeams(2.5, []string{}, "rating", 0).Return(teamsSearch, nil)
+
+	writer = postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		MaxRating:     2.5,
+		Users:         users,
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	require.NotNil(t, problem.AvailableTeams)
+	assert.Zero(t, *problem.AvailableTeams)
+}
+
+type fixedSeedSource int64
+
+func (s fixedSeedSource) Seed() int64 {
+	return int64(s)
+}
+
+func TestHTTPServer_GenerateTourney_Seed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	teamsSearch := readTeams(t, "../../test/data/search_teams_payload.js")
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).AnyTimes().Return(teamsSearch, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	router := newRouter(teamsService, fixedSeedSource(42))
+
+	seed := int64(7)
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 4, RequiredTeams: []string{liverpoolID}},
+			{UserID: user2ID, TeamsCount: 4},
+			{UserID: user3ID, TeamsCount: 4, RequiredTeams: []string{milanID, bayernID}},
+			{UserID: user4ID, TeamsCount: 4},
+		},
+	}
+
+	generate := func(tourneyRequest ports.GenerateTourneyRequest) []converter.TourneyDTO {
+		writer := postJSON(t, router, "/tourneys", tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		return withoutIdentity(result)
+	}
+
+	first := generate(tourneyRequest)
+	assert.Equal(t, seed, first[0].Seed)
+	assert.Equal(t, first, generate(tourneyRequest))
+
+	tourneyRequest.Seed = nil
+	unseeded := generate(tourneyRequest)
+	assert.Equal(t, int64(42), unseeded[0].Seed)
+
+	tourneyRequest.Seed = &unseeded[0].Seed
+	assert.Equal(t, unseeded, generate(tourneyRequest))
+}
+
+func TestHTTPServer_GenerateTourney_PotsDrawMode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).Return(allTeams, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   8,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		DrawMode:      service.DrawModePots,
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 8, RequiredTeams: []string{liverpoolID}},
+			{UserID: user2ID, TeamsCount: 8},
+			{UserID: user3ID, TeamsCount: 8, RequiredTeams: []string{milanID, bayernID}},
+			{UserID: user4ID, TeamsCount: 8},
+		},
+	})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []dto.Tourney
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+
+	var ratings []float64
+
+	groupsRatings := make([][]float64, len(result[0].Groups))
+
+	for i, group := range result[0].Groups {
+		for _, slot := range group.TeamSlots {
+			ratings = append(ratings, slot.Team.Rating)
+			groupsRatings[i] = append(groupsRatings[i], slot.Team.Rating)
+		}
+
+		sort.Sort(sort.Reverse(sort.Float64Slice(groupsRatings[i])))
+	}
+
+	sort.Sort(sort.Reverse(sort.Float64Slice(ratings)))
+
+	for _, groupRatings := range groupsRatings {
+		for potIndex, rating := range groupRatings {
+			pot := ratings[potIndex*len(groupsRatings) : (potIndex+1)*len(groupsRatings)]
+			assert.LessOrEqual(t, rating, pot[0])
+			assert.GreaterOrEqual(t, rating, pot[len(pot)-1])
+		}
+	}
+}
+
+func TestHTTPServer_GenerateTourney_LeagueProtection(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	users := []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}, {UserID: user3ID}}
+	seed := int64(1)
+
+	for _, leagueProtection := range []string{service.LeagueProtectionLeague, service.LeagueProtectionCountry} {
+		for _, drawMode := range []string{service.DrawModeClassic, service.DrawModePots} {
+			writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+				GroupsCount:      10,
+				TeamsPerGroup:    3,
+				Leagues:          []string{},
+				DrawMode:         drawMode,
+				LeagueProtection: leagueProtection,
+				Seed:             &seed,
+				Users:            users,
+			})
+			require.Equal(t, http.StatusOK, writer.Code, leagueProtection, drawMode)
+
+			var result []converter.TourneyDTO
+			require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+			require.Len(t, result, 1)
+			require.NotNil(t, result[0].Report)
+			require.Len(t, result[0].Report.Groups, 10)
+			assert.Zero(t, result[0].Report.SameUserPairs)
+
+			if leagueProtection == service.LeagueProtectionLeague {
+				assert.Zero(t, result[0].Report.SameLeaguePairs)
+			}
+
+			for _, group := range result[0].Groups {
+				leagues := make(map[string]bool, len(group.TeamSlots))
+				for _, slot := range group.TeamSlots {
+					assert.False(t, leagues[slot.Team.League], "group %s has two teams of %s", group.Name, slot.Team.League)
+					leagues[slot.Team.League] = true
+				}
+			}
+		}
+	}
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:      8,
+		TeamsPerGroup:    4,
+		Leagues:          []string{},
+		LeagueProtection: service.LeagueProtectionLeague,
+		Users:            users,
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_CountryProtection(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	// the leagues without a country aren't protected, the leagues of a country are protected together.
+	teams := []dto.Team{
+		{ID: "rw-1", Name: "Rest 1", Rating: 4, League: "Rest of World"},
+		{ID: "rw-2", Name: "Rest 2", Rating: 4, League: "Rest of World"},
+		{ID: "rw-3", Name: "Rest 3", Rating: 4, League: "Rest of World"},
+		{ID: "fa-1", Name: "Free 1", Rating: 4, League: "Free Agents League"},
+		{ID: "en-1", Name: "England 1", Rating: 4, League: "England Premier League (1)"},
+		{ID: "en-2", Name: "England 2", Rating: 4, League: "England Championship (2)"},
+	}
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).AnyTimes().Return(teams, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(teams, nil)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:      2,
+		TeamsPerGroup:    3,
+		Leagues:          []string{},
+		LeagueProtection: service.LeagueProtectionCountry,
+		Users:            []ports.UserParams{{UserID: user1ID, TeamsCount: 3}, {UserID: user2ID, TeamsCount: 3}},
+	}
+
+	writer := postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+
+	for _, group := range result[0].Groups {
+		englandTeams := 0
+
+		for _, slot := range group.TeamSlots {
+			if strings.HasPrefix(slot.Team.ID, "en-") {
+				englandTeams++
+			}
+		}
+
+		assert.Equal(t, 1, englandTeams, group.Name)
+	}
+
+	tourneyRequest.LeagueProtection = service.LeagueProtectionLeague
+	writer = postTourney(t, teamsService, tourneyRequest)
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_UserSpread(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	seed := int64(3)
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   8,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 8},
+			{UserID: user2ID, TeamsCount: 6},
+			{UserID: user3ID, TeamsCount: 8},
+			{UserID: user4ID, TeamsCount: 10},
+		},
+	}
+
+	for _, drawMode := range []string{service.DrawModeClassic, service.DrawModePots} {
+		tourneyRequest.DrawMode = drawMode
+
+		writer := postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []dto.Tourney
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		for _, group := range result[0].Groups {
+			slotsCountByUserID := make(map[string]int, len(tourneyRequest.Users))
+			for _, slot := range group.TeamSlots {
+				slotsCountByUserID[slot.UserID]++
+			}
+
+			assert.LessOrEqual(t, slotsCountByUserID[user1ID], 1)
+			assert.LessOrEqual(t, slotsCountByUserID[user2ID], 1)
+			assert.LessOrEqual(t, slotsCountByUserID[user3ID], 1)
+			assert.LessOrEqual(t, slotsCountByUserID[user4ID], 2)
+		}
+	}
+
+	tourneyRequest.MaxUserSlotsPerGroup = 1
+	writer := postTourney(t, teamsService, tourneyRequest)
+	assert.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+
+	// the cap of a disabled user spread would be ignored, so it's rejected.
+	userSpread := false
+	tourneyRequest.UserSpread = &userSpread
+	writer = postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	require.Len(t, problem.InvalidParams, 1)
+	assert.Equal(t, "max_user_slots_per_group", problem.InvalidParams[0].Name)
+
+	tourneyRequest.MaxUserSlotsPerGroup = 0
+	writer = postTourney(t, teamsService, tourneyRequest)
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_BalancedAssignment(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:    8,
+		TeamsPerGroup:  4,
+		Leagues:        []string{},
+		AssignmentMode: service.AssignmentModeBalanced,
+		Users: []ports.UserParams{
+			{UserID: user1ID, TeamsCount: 8, RequiredTeams: []string{liverpoolID}},
+			{UserID: user2ID, TeamsCount: 6},
+			{UserID: user3ID, TeamsCount: 8, RequiredTeams: []string{milanID, bayernID}},
+			{UserID: user4ID, TeamsCount: 10, RequiredTeams: []string{sevillaID}},
+		},
+	})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+	require.NotNil(t, result[0].Report)
+	require.Len(t, result[0].Report.Users, 4)
+
+	ratingTotalByUserID := make(map[string]float64)
+
+	for _, group := range result[0].Groups {
+		for _, slot := range group.TeamSlots {
+			ratingTotalByUserID[slot.UserID] += slot.Team.Rating
+		}
+	}
+
+	minAverage, maxAverage := result[0].Report.Users[0].RatingAverage, result[0].Report.Users[0].RatingAverage
+
+	for _, user := range result[0].Report.Users {
+		assert.InDelta(t, ratingTotalByUserID[user.UserID], user.RatingTotal, 1e-9)
+		minAverage = math.Min(minAverage, user.RatingAverage)
+		maxAverage = math.Max(maxAverage, user.RatingAverage)
+	}
+
+	assert.LessOrEqual(t, maxAverage-minAverage, 0.1)
+}
+
+func TestHTTPServer_GenerateTourney_Report(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).Return(allTeams, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	userSpread := false
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		UserSpread:    &userSpread,
+		Users:         []ports.UserParams{{UserID: user1ID, TeamsCount: 10}, {UserID: user2ID, TeamsCount: 6}},
+	})
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+
+	report := result[0].Report
+	require.NotNil(t, report)
+	require.Len(t, report.Groups, len(result[0].Groups))
+
+	sameLeaguePairs, sameUserPairs := 0, 0
+
+	for i, group := range result[0].Groups {
+		groupSameLeaguePairs, groupSameUserPairs, ratingTotal := 0, 0, 0.0
+
+		for j, slot := range group.TeamSlots {
+			ratingTotal += slot.Team.Rating
+
+			for _, other := range group.TeamSlots[j+1:] {
+				if slot.Team.League == other.Team.League {
+					groupSameLeaguePairs++
+				}
+
+				if slot.UserID == other.UserID {
+					groupSameUserPairs++
+				}
+			}
+		}
+
+		assert.Equal(t, group.Name, report.Groups[i].Name)
+		assert.InDelta(t, ratingTotal/float64(len(group.TeamSlots)), report.Groups[i].RatingMean, 1e-9)
+		assert.GreaterOrEqual(t, report.Groups[i].RatingVariance, float64(0))
+		assert.Equal(t, groupSameLeaguePairs, report.Groups[i].SameLeaguePairs)
+		assert.Equal(t, groupSameUserPairs, report.Groups[i].SameUserPairs)
+
+		sameLeaguePairs += groupSameLeaguePairs
+		sameUserPairs += groupSameUserPairs
+	}
+
+	assert.Equal(t, sameLeaguePairs, report.SameLeaguePairs)
+	assert.Equal(t, sameUserPairs, report.SameUserPairs)
+	assert.Positive(t, report.SameUserPairs)
+}
+
+func TestHTTPServer_GenerateTourney_Candidates(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouter(teamsService, fixedSeedSource(11))
+	generate := func(tourneyRequest ports.GenerateTourneyRequest) []converter.TourneyDTO {
+		writer := postJSON(t, router, "/tourneys", tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+
+		return withoutIdentity(result)
+	}
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}, {UserID: user3ID}, {UserID: user4ID}},
+	}
+
+	single := generate(tourneyRequest)
+	require.Len(t, single, 1)
+
+	tourneyRequest.Candidates = 20
+	tourneyRequest.Top = 3
+	tourneyRequest.Objective = service.ObjectiveGroupRatingVariance
+
+	best := generate(tourneyRequest)
+	require.Len(t, best, 3)
+	assert.LessOrEqual(t, groupMeansVariance(best[0]), groupMeansVariance(single[0]))
+	assert.LessOrEqual(t, groupMeansVariance(best[0]), groupMeansVariance(best[1]))
+	assert.LessOrEqual(t, groupMeansVariance(best[1]), groupMeansVariance(best[2]))
+
+	tourneyRequest.Candidates, tourneyRequest.Top = 0, 0
+	tourneyRequest.Seed = &best[1].Seed
+	assert.Equal(t, best[1:2], generate(tourneyRequest))
+
+	tourneyRequest.Candidates, tourneyRequest.Top = 2, 3
+	writer := postJSON(t, router, "/tourneys", tourneyRequest)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_Rebalance(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouter(teamsService, fixedSeedSource(5))
+	generate := func(tourneyRequest ports.GenerateTourneyRequest) converter.TourneyDTO {
+		writer := postJSON(t, router, "/tourneys", tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		return result[0]
+	}
+
+	teamIDs := func(tourney converter.TourneyDTO) []string {
+		var result []string
+
+		for _, group := range tourney.Groups {
+			for _, slot := range group.TeamSlots {
+				result = append(result, slot.Team.ID)
+			}
+		}
+
+		sort.Strings(result)
+
+		return result
+	}
+
+	for _, drawMode := range []string{service.DrawModeClassic, service.DrawModePots} {
+		tourneyRequest := ports.GenerateTourneyRequest{
+			GroupsCount:      8,
+			TeamsPerGroup:    3,
+			Leagues:          []string{},
+			DrawMode:         drawMode,
+			LeagueProtection: service.LeagueProtectionLeague,
+			Users:            []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}, {UserID: user3ID}},
+		}
+
+		drawn := generate(tourneyRequest)
+
+		tourneyRequest.RebalanceIterations = 2000
+		rebalanced := generate(tourneyRequest)
+
+		assert.Equal(t, teamIDs(drawn), teamIDs(rebalanced), drawMode)
+		assert.LessOrEqual(t, groupMeansVariance(rebalanced), groupMeansVariance(drawn), drawMode)
+
+		if drawMode == service.DrawModeClassic {
+			assert.Less(t, groupMeansVariance(rebalanced), groupMeansVariance(drawn))
+		}
+		assert.Zero(t, rebalanced.Report.SameLeaguePairs, drawMode)
+		assert.Zero(t, rebalanced.Report.SameUserPairs, drawMode)
+	}
+
+	writer := postJSON(t, router, "/tourneys", ports.GenerateTourneyRequest{
+		GroupsCount:         2,
+		TeamsPerGroup:       2,
+		Leagues:             []string{},
+		RebalanceIterations: -1,
+		Users:               []ports.UserParams{{UserID: user1ID}},
+	})
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_Constraints(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	users := []ports.UserParams{
+		{UserID: user1ID, RequiredTeams: []string{liverpoolID, milanID}},
+		{UserID: user2ID, RequiredTeams: []string{sevillaID, bayernID}},
+	}
+
+	for _, drawMode := range []string{service.DrawModeClassic, service.DrawModePots} {
+		writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+			GroupsCount:   4,
+			TeamsPerGroup: 3,
+			Leagues:       []string{},
+			DrawMode:      drawMode,
+			Constraints:   []string{service.ConstraintRivalSeparation, service.ConstraintPots},
+			Rivals:        [][]string{{liverpoolID, milanID, sevillaID, bayernID}},
+			Users:         users,
+		})
+		require.Equal(t, http.StatusOK, writer.Code, drawMode)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		for _, group := range result[0].Groups {
+			rivalsCount := 0
+
+			for _, slot := range group.TeamSlots {
+				switch slot.Team.ID {
+				case liverpoolID, milanID, sevillaID, bayernID:
+					rivalsCount++
+				}
+			}
+
+			assert.Equal(t, 1, rivalsCount, "group %s", group.Name)
+		}
+	}
+
+	infeasibleRequests := []ports.GenerateTourneyRequest{
+		{
+			GroupsCount:   3,
+			TeamsPerGroup: 4,
+			Leagues:       []string{},
+			Rivals:        [][]string{{liverpoolID, milanID, sevillaID, bayernID}},
+			Users:         users,
+		},
+		{
+			GroupsCount:   2,
+			TeamsPerGroup: 2,
+			Leagues:       []string{},
+			Constraints:   []string{service.ConstraintUserSpread, service.ConstraintRivalSeparation},
+			Rivals:        [][]string{{liverpoolID, sevillaID}, {liverpoolID, bayernID}},
+			Users:         users,
+		},
+	}
+
+	for _, tourneyRequest := range infeasibleRequests {
+		writer := postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+		assert.Contains(t, writer.Body.String(), "constraint `")
+	}
+
+	assert.Contains(t, postTourney(t, teamsService, infeasibleRequests[0]).Body.String(), service.ConstraintRivalSeparation)
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 2,
+		Leagues:       []string{},
+		Constraints:   []string{"unknown"},
+		Users:         users,
+	})
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_PoolExhausted(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(5), []string{}, "rating", 0).Times(2).Return(allTeams, nil)
+	teamsService.EXPECT().SearchTeams(4.5, []string{}, "rating", 0).Return(allTeams, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).Return(allTeams, nil)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   4,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		MinRating:     5,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	writer := postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	assert.Equal(t, "rating range [5, 5] yields 10 teams, 16 needed", problem.Detail)
+	require.NotNil(t, problem.AvailableTeams)
+	require.NotNil(t, problem.NeededTeams)
+	assert.Equal(t, 10, *problem.AvailableTeams)
+	assert.Equal(t, 16, *problem.NeededTeams)
+
+	tourneyRequest.AllowLowerRatings = true
+	writer = postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+
+	teamIDs := make(map[string]bool)
+
+	for _, group := range result[0].Groups {
+		for _, slot := range group.TeamSlots {
+			assert.GreaterOrEqual(t, slot.Team.Rating, 4.5)
+			assert.NotEmpty(t, slot.Team.ID)
+			assert.False(t, teamIDs[slot.Team.ID], "team %s is drawn twice", slot.Team.ID)
+			teamIDs[slot.Team.ID] = true
+		}
+	}
+
+	assert.Len(t, teamIDs, 16)
+}
+
+func TestHTTPServer_GenerateTourney_Validation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).Return(nil, errors.New("teams service is down"))
+
+	decodeProblem := func(writer *httptest.ResponseRecorder) ports.Problem {
+		assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+
+		var problem ports.Problem
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+		assert.Equal(t, writer.Code, problem.Status)
+
+		return problem
+	}
+
+	writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   0,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Candidates:    -1,
+		Users: []ports.UserParams{
+			{UserID: user1ID, RequiredTeams: []string{liverpoolID}},
+			{UserID: user1ID},
+			{UserID: user2ID, TeamsCount: -1, RequiredTeams: []string{liverpoolID}},
+			{UserID: user3ID, RequiredTeams: []string{milanID, milanID}},
+			{UserID: ""},
+			{UserID: "nobody"},
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	invalidParams := decodeProblem(writer).InvalidParams
+	names := make([]string, len(invalidParams))
+
+	for i, invalidParam := range invalidParams {
+		names[i] = invalidParam.Name
+		assert.NotEmpty(t, invalidParam.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"groups_count",
+		"candidates",
+		"users[1].user_id",
+		"users[2].teams_count",
+		"users[2].required_teams[0]",
+		"users[3].required_teams[1]",
+		"users[4].user_id",
+		"users[5].user_id",
+	}, names)
+	assert.Equal(t, "team `"+liverpoolID+"` is required by users[0] too", invalidParams[4].Reason)
+	assert.Equal(t, "team `"+milanID+"` is listed twice", invalidParams[5].Reason)
+
+	request, err := http.NewRequestWithContext(
+		context.Background(), http.MethodPost, "/tourneys", bytes.NewBufferString("{"),
+	)
+	require.NoError(t, err)
+
+	writer = httptest.NewRecorder()
+	newRouter(teamsService, service.TimeSeedSource{}).ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	decodeProblem(writer)
+
+	writer = postTourney(t, teamsService, ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 2,
+		Leagues:       []string{},
+		Users:         []ports.UserParams{{UserID: user1ID}},
+	})
+	require.Equal(t, http.StatusBadGateway, writer.Code)
+	decodeProblem(writer)
+}
+
+func TestHTTPServer_GenerateTourney_SettingsValidation(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	tests := []struct {
+		name   string
+		modify func(request *ports.GenerateTourneyRequest)
+		field  string
+	}{
+		{
+			name:   "unknown group naming",
+			modify: func(request *ports.GenerateTourneyRequest) { request.GroupNaming = "greek" },
+			field:  "group_naming",
+		},
+		{
+			name: "required teams exceeding the auto slots",
+			modify: func(request *ports.GenerateTourneyRequest) {
+				request.Users = []ports.UserParams{
+					{UserID: user1ID, TeamsCount: 3},
+					{UserID: user2ID, RequiredTeams: []string{liverpoolID, milanID}},
+				}
+			},
+			field: "users[1].required_teams",
+		},
+		{
+			name:   "huge group size",
+			modify: func(request *ports.GenerateTourneyRequest) { request.TeamsPerGroup = 1 << 40 },
+			field:  "teams_per_group",
+		},
+		{
+			name: "too many slots",
+			modify: func(request *ports.GenerateTourneyRequest) {
+				request.GroupsCount, request.TeamsPerGroup = service.MaxGroupsCount, 32
+			},
+			field: "teams_per_group",
+		},
+		{
+			name:   "too many groups",
+			modify: func(request *ports.GenerateTourneyRequest) { request.GroupsCount = service.MaxGroupsCount + 1 },
+			field:  "groups_count",
+		},
+		{
+			name:   "too many teams",
+			modify: func(request *ports.GenerateTourneyRequest) { request.TeamsPerGroup, request.TeamsCount = 0, 1<<40 },
+			field:  "teams_count",
+		},
+		{
+			name:   "unknown draw mode",
+			modify: func(request *ports.GenerateTourneyRequest) { request.DrawMode = "lottery" },
+			field:  "draw_mode",
+		},
+		{
+			name:   "rating range",
+			modify: func(request *ports.GenerateTourneyRequest) { request.MinRating = 4.25 },
+			field:  "min_rating",
+		},
+		{
+			name:   "top above candidates",
+			modify: func(request *ports.GenerateTourneyRequest) { request.Candidates, request.Top = 2, 3 },
+			field:  "top",
+		},
+		{
+			name:   "unknown tiebreaker",
+			modify: func(request *ports.GenerateTourneyRequest) { request.Tiebreakers = []string{"points", "coin"} },
+			field:  "tiebreakers[1]",
+		},
+		{
+			name:   "third place match without knockout",
+			modify: func(request *ports.GenerateTourneyRequest) { request.ThirdPlaceMatch = true },
+			field:  "third_place_match",
+		},
+	}
+
+	for _, testCase := range tests {
+		tourneyRequest := ports.GenerateTourneyRequest{
+			GroupsCount:   2,
+			TeamsPerGroup: 2,
+			Leagues:       []string{},
+			Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+		}
+		testCase.modify(&tourneyRequest)
+
+		writer := postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusBadRequest, writer.Code, testCase.name)
+
+		var problem ports.Problem
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem), testCase.name)
+		require.Len(t, problem.InvalidParams, 1, testCase.name)
+		assert.Equal(t, testCase.field, problem.InvalidParams[0].Name, testCase.name)
+		assert.NotEmpty(t, problem.InvalidParams[0].Reason, testCase.name)
+		assert.Empty(t, problem.Detail, testCase.name)
+	}
+}
+
+func TestHTTPServer_GenerateTourney_RequiredTeamsPolicy(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Users: []ports.UserParams{
+			{UserID: user1ID, RequiredTeams: []string{liverpoolID}},
+			{UserID: user2ID, RequiredTeams: []string{milanID, liverpoolID}},
+			{UserID: user3ID, RequiredTeams: []string{liverpoolID}},
+			{UserID: user4ID},
+		},
+	}
+
+	writer := postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	claimantIDs := []string{user1ID, user2ID, user3ID}
+
+	for policy, expectedOwnerIDs := range map[string][]string{
+		service.RequiredTeamsPolicyFirstCome:        {user1ID},
+		service.RequiredTeamsPolicyRandomWinner:     nil,
+		service.RequiredTeamsPolicyDuplicateAllowed: claimantIDs,
+	} {
+		tourneyRequest.RequiredTeamsPolicy = policy
+		writer = postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code, policy)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+		require.Len(t, result[0].ContestedTeams, 1, policy)
+
+		contestedTeam := result[0].ContestedTeams[0]
+		assert.Equal(t, liverpoolID, contestedTeam.TeamID)
+		assert.Equal(t, claimantIDs, contestedTeam.ClaimantIDs)
+
+		if expectedOwnerIDs != nil {
+			assert.Equal(t, expectedOwnerIDs, contestedTeam.OwnerIDs, policy)
+		} else {
+			require.Len(t, contestedTeam.OwnerIDs, 1)
+			assert.Contains(t, claimantIDs, contestedTeam.OwnerIDs[0])
+		}
+
+		var liverpoolOwnerIDs []string
+
+		for _, group := range result[0].Groups {
+			for _, slot := range group.TeamSlots {
+				if slot.Team.ID == liverpoolID {
+					liverpoolOwnerIDs = append(liverpoolOwnerIDs, slot.UserID)
+				}
+			}
+		}
+
+		sort.Strings(liverpoolOwnerIDs)
+
+		ownerIDs := append([]string(nil), contestedTeam.OwnerIDs...)
+		sort.Strings(ownerIDs)
+		assert.Equal(t, ownerIDs, liverpoolOwnerIDs, policy)
+	}
+}
+
+func TestHTTPServer_GenerateTourney_UnknownRequiredTeams(t *testing.T) {
+	t.Parallel()
+
+	const unknownTeamID = "00000000-0000-0000-0000-000000000000"
+
+	allTeams := readTeams(t, "../../test/data/teams.json")
+	teamsByID := make(map[string]dto.Team, len(allTeams))
+
+	for _, team := range allTeams {
+		teamsByID[team.ID] = team
+	}
+
+	teamsService := newTeamsServiceMock(t)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Users: []ports.UserParams{
+			{UserID: user1ID, RequiredTeams: []string{liverpoolID, unknownTeamID}},
+			{UserID: user2ID},
+		},
+	}
+
+	writer := postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusUnprocessableEntity, writer.Code)
+	assert.Contains(t, writer.Body.String(), unknownTeamID)
+
+	tourneyRequest.SubstituteUnknown = true
+	writer = postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Substitutions, 1)
+
+	substitution := result[0].Substitutions[0]
+	assert.Equal(t, user1ID, substitution.UserID)
+	assert.Equal(t, unknownTeamID, substitution.RequestedTeamID)
+	assert.Equal(t, teamsByID[liverpoolID].Rating, teamsByID[substitution.TeamID].Rating)
+
+	ownerIDByTeamID := make(map[string]string)
+
+	for _, group := range result[0].Groups {
+		for _, slot := range group.TeamSlots {
+			ownerIDByTeamID[slot.Team.ID] = slot.UserID
+		}
+	}
+
+	assert.Equal(t, user1ID, ownerIDByTeamID[liverpoolID])
+	assert.Equal(t, user1ID, ownerIDByTeamID[substitution.TeamID])
+	assert.NotContains(t, ownerIDByTeamID, unknownTeamID)
+}
+
+func TestHTTPServer_GenerateTourney_GroupNames(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	groupNames := func(tourneyRequest ports.GenerateTourneyRequest) []string {
+		writer := postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		names := make([]string, len(result[0].Groups))
+		for i, group := range result[0].Groups {
+			names[i] = group.Name
+		}
+
+		return names
+	}
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   28,
+		TeamsPerGroup: 2,
+		Leagues:       []string{},
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	// the groups keep their lowercase names by default.
+	names := groupNames(tourneyRequest)
+	require.Len(t, names, 28)
+	assert.Equal(t, []string{"a", "b", "c"}, names[:3])
+	assert.Equal(t, []string{"y", "z", "aa", "ab"}, names[24:])
+
+	tourneyRequest.GroupNaming = service.GroupNamingLetters
+	names = groupNames(tourneyRequest)
+	assert.Equal(t, []string{"A", "B", "C"}, names[:3])
+	assert.Equal(t, []string{"Y", "Z", "AA", "AB"}, names[24:])
+
+	tourneyRequest.GroupsCount = 3
+	tourneyRequest.GroupNaming = service.GroupNamingNumbers
+	assert.Equal(t, []string{"1", "2", "3"}, groupNames(tourneyRequest))
+
+	tourneyRequest.GroupNames = []string{"North", "East", "South"}
+	writer := postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	var problem ports.Problem
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	require.Len(t, problem.InvalidParams, 1)
+	assert.Equal(t, "group_names", problem.InvalidParams[0].Name)
+
+	tourneyRequest.GroupNaming = ""
+	assert.Equal(t, tourneyRequest.GroupNames, groupNames(tourneyRequest))
+
+	tourneyRequest.GroupNames = []string{"North", "East", "North", "West"}
+	writer = postTourney(t, teamsService, tourneyRequest)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	problem = ports.Problem{}
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&problem))
+	require.Len(t, problem.InvalidParams, 2)
+	assert.Equal(t, "group_names", problem.InvalidParams[0].Name)
+	assert.Equal(t, "group_names[2]", problem.InvalidParams[1].Name)
+
+	tourneyRequest.GroupNames = nil
+	tourneyRequest.GroupNaming = "roman"
+	writer = postTourney(t, teamsService, tourneyRequest)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestHTTPServer_GenerateTourney_UnevenGroups(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	for _, tourneyRequest := range []ports.GenerateTourneyRequest{
+		{DrawMode: service.DrawModeClassic},
+		{DrawMode: service.DrawModePots},
+		{DrawMode: service.DrawModePots, RebalanceIterations: 100, Rivals: [][]string{{liverpoolID, milanID}}},
+	} {
+		tourneyRequest.GroupsCount = 4
+		tourneyRequest.TeamsCount = 14
+		tourneyRequest.Leagues = []string{}
+		tourneyRequest.Users = []ports.UserParams{{UserID: user1ID, TeamsCount: 5}, {UserID: user2ID}, {UserID: user3ID}}
+
+		writer := postTourney(t, teamsService, tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code, tourneyRequest.DrawMode)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		sizes := make([]int, len(result[0].Groups))
+		teamIDs := make(map[string]bool)
+
+		for i, group := range result[0].Groups {
+			sizes[i] = group.Size
+			require.Len(t, group.TeamSlots, group.Size)
+
+			for _, slot := range group.TeamSlots {
+				assert.NotEmpty(t, slot.Team.ID)
+				teamIDs[slot.Team.ID] = true
+			}
+		}
+
+		assert.Equal(t, []int{4, 4, 3, 3}, sizes)
+		assert.Len(t, teamIDs, 14)
+	}
+
+	for _, tourneyRequest := range []ports.GenerateTourneyRequest{
+		{GroupsCount: 4, TeamsCount: 3},
+		{GroupsCount: 4, TeamsCount: 14, TeamsPerGroup: 4},
+	} {
+		tourneyRequest.Leagues = []string{}
+		tourneyRequest.Users = []ports.UserParams{{UserID: user1ID}}
+
+		writer := postTourney(t, teamsService, tourneyRequest)
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+	}
+}
+
+func TestHTTPServer_GenerateTourney_Fixtures(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	for _, doubleRoundRobin := range []bool{false, true} {
+		writer := postTourney(t, teamsService, ports.GenerateTourneyRequest{
+			GroupsCount:      4,
+			TeamsCount:       14,
+			Leagues:          []string{},
+			DoubleRoundRobin: doubleRoundRobin,
+			Users:            []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+		})
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+
+		legs := 1
+		if doubleRoundRobin {
+			legs = 2
+		}
+
+		for _, group := range result[0].Groups {
+			roundsCount := group.Size - 1 + group.Size%2
+			require.Len(t, group.Matchdays, legs*roundsCount, group.Name)
+
+			meetings := make(map[[2]int]int)
+			byesCount := make(map[int]int)
+			homeCount := make(map[int]int)
+
+			for i, matchday := range group.Matchdays {
+				assert.Equal(t, i+1, matchday.Number)
+				assert.Len(t, matchday.Fixtures, group.Size/2)
+
+				playing := make(map[int]bool)
+
+				for _, fixture := range matchday.Fixtures {
+					assert.Equal(t, group.TeamSlots[fixture.HomeSlot], fixture.Home)
+					assert.Equal(t, group.TeamSlots[fixture.AwaySlot], fixture.Away)
+					assert.False(t, playing[fixture.HomeSlot] || playing[fixture.AwaySlot])
+
+					playing[fixture.HomeSlot], playing[fixture.AwaySlot] = true, true
+					meetings[[2]int{fixture.HomeSlot, fixture.AwaySlot}]++
+					homeCount[fixture.HomeSlot]++
+				}
+
+				if group.Size%2 == 0 {
+					assert.Nil(t, matchday.Bye)
+
+					continue
+				}
+
+				require.NotNil(t, matchday.Bye)
+
+				for slotIndex, slot := range group.TeamSlots {
+					if !playing[slotIndex] {
+						assert.Equal(t, slot, *matchday.Bye)
+						byesCount[slotIndex]++
+					}
+				}
+			}
+
+			for home := 0; home < group.Size; home++ {
+				if group.Size%2 == 1 {
+					assert.Equal(t, legs, byesCount[home])
+				}
+
+				for away := home + 1; away < group.Size; away++ {
+					pair := [2]int{home, away}
+					reversed := [2]int{away, home}
+
+					assert.Equal(t, legs, meetings[pair]+meetings[reversed], "%s: %v", group.Name, pair)
+
+					if doubleRoundRobin {
+						assert.Equal(t, 1, meetings[pair])
+					}
+				}
+
+				assert.InDelta(t, legs*(group.Size-1), 2*homeCount[home], float64(legs))
+			}
+		}
+	}
+}
+
+func TestHTTPServer_GenerateTourney_Knockout(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	generate := func(groupsCount, qualifiers int, thirdPlace bool) *httptest.ResponseRecorder {
+		return postTourney(t, teamsService, ports.GenerateTourneyRequest{
+			GroupsCount:        groupsCount,
+			TeamsPerGroup:      4,
+			Leagues:            []string{},
+			KnockoutQualifiers: qualifiers,
+			ThirdPlaceMatch:    thirdPlace,
+			Users:              []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
 		})
 	}
+
+	decode := func(writer *httptest.ResponseRecorder) *converter.KnockoutDTO {
+		require.Equal(t, http.StatusOK, writer.Code, writer.Body.String())
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+		require.Len(t, result, 1)
+		require.NotNil(t, result[0].Knockout)
+
+		return result[0].Knockout
+	}
+
+	knockout := decode(generate(4, 2, true))
+	require.Len(t, knockout.Rounds, 3)
+	assert.Equal(t, "Quarter-finals", knockout.Rounds[0].Name)
+	assert.Equal(t, "Semi-finals", knockout.Rounds[1].Name)
+	assert.Equal(t, "Final", knockout.Rounds[2].Name)
+	require.Len(t, knockout.Rounds[0].Ties, 4)
+
+	halvesByGroup := make(map[string][]int)
+
+	for i, tie := range knockout.Rounds[0].Ties {
+		assert.Equal(t, i+1, tie.Number)
+		assert.Equal(t, 1, tie.Home.Position)
+		assert.Equal(t, 2, tie.Away.Position)
+		assert.NotEqual(t, tie.Home.Group, tie.Away.Group)
+		assert.Equal(t, "Winner of group "+tie.Home.Group, tie.Home.Placeholder)
+		assert.Equal(t, "Runner-up of group "+tie.Away.Group, tie.Away.Placeholder)
+
+		halvesByGroup[tie.Home.Group] = append(halvesByGroup[tie.Home.Group], i/2)
+		halvesByGroup[tie.Away.Group] = append(halvesByGroup[tie.Away.Group], i/2)
+	}
+
+	require.Len(t, halvesByGroup, 4)
+
+	for group, halves := range halvesByGroup {
+		require.Len(t, halves, 2, group)
+		assert.NotEqual(t, halves[0], halves[1], "teams of group %s meet before the final", group)
+	}
+
+	require.NotNil(t, knockout.ThirdPlace)
+	assert.Equal(t, 7, knockout.ThirdPlace.Number)
+	assert.Equal(t, converter.KnockoutSlotDTO{Placeholder: "Loser of tie 5", Tie: 5, Loser: true}, knockout.ThirdPlace.Home)
+	assert.Equal(t, converter.KnockoutSlotDTO{Placeholder: "Loser of tie 6", Tie: 6, Loser: true}, knockout.ThirdPlace.Away)
+	assert.Equal(t, 8, knockout.Rounds[2].Ties[0].Number)
+	assert.Equal(t, "Winner of tie 5", knockout.Rounds[2].Ties[0].Home.Placeholder)
+
+	// six qualifiers leave byes to the two best group winners.
+	knockout = decode(generate(3, 2, false))
+	require.Len(t, knockout.Rounds, 3)
+	assert.Nil(t, knockout.ThirdPlace)
+	require.Len(t, knockout.Rounds[0].Ties, 2)
+	require.Len(t, knockout.Rounds[1].Ties, 2)
+
+	for i, tie := range knockout.Rounds[1].Ties {
+		assert.Equal(t, i+1, tie.Home.Seed)
+		assert.Equal(t, 1, tie.Home.Position)
+		assert.Equal(t, i+1, tie.Away.Tie)
+	}
+
+	for _, tie := range knockout.Rounds[0].Ties {
+		assert.NotEqual(t, tie.Home.Group, tie.Away.Group)
+	}
+
+	for _, invalid := range []struct {
+		groupsCount, qualifiers int
+		thirdPlace              bool
+	}{
+		{groupsCount: 2, qualifiers: 5},
+		{groupsCount: 1, qualifiers: 2, thirdPlace: true},
+		{groupsCount: 2, qualifiers: 0, thirdPlace: true},
+		{groupsCount: 2, qualifiers: -1},
+	} {
+		assert.Equal(t, http.StatusBadRequest, generate(invalid.groupsCount, invalid.qualifiers, invalid.thirdPlace).Code, invalid)
+	}
+}
+
+func TestHTTPServer_GenerateTourney_Persisted(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	tourneys := adapters.NewMemoryTourneyRepository()
+	router := newRouterWithRepository(teamsService, service.TimeSeedSource{}, tourneys)
+
+	seed := int64(11)
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Seed:          &seed,
+		Candidates:    3,
+		Top:           2,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	writer := postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 2)
+	assert.NotEqual(t, result[0].ID, result[1].ID)
+
+	for _, tourneyDTO := range result {
+		assert.Regexp(t, "^[0-9a-f]{32}$", tourneyDTO.ID)
+		assert.False(t, tourneyDTO.CreatedAt.IsZero())
+
+		tourney, err := tourneys.Get(tourneyDTO.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tourneyDTO.Seed, tourney.Seed())
+		assert.True(t, tourneyDTO.CreatedAt.Equal(tourney.CreatedAt()))
+
+		for i, group := range tourney.Groups() {
+			assert.Equal(t, tourneyDTO.Groups[i].Name, group.Name())
+
+			for j, slot := range group.TeamSlots() {
+				assert.Equal(t, tourneyDTO.Groups[i].TeamSlots[j].Team.ID, slot.TeamID())
+			}
+		}
+	}
+
+	_, err := tourneys.Get("unknown")
+	assert.ErrorIs(t, err, repository.ErrTourneyNotFound)
+}
+
+func TestHTTPServer_GenerateTourney_StorageFailure(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	tourneys := &limitedTourneyRepository{MemoryTourneyRepository: adapters.NewMemoryTourneyRepository(), savesLeft: 1}
+	router := newRouterWithRepository(teamsService, service.TimeSeedSource{}, tourneys)
+
+	tourneyRequest := ports.GenerateTourneyRequest{
+		GroupsCount:   2,
+		TeamsPerGroup: 4,
+		Leagues:       []string{},
+		Candidates:    3,
+		Top:           2,
+		Users:         []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}},
+	}
+
+	// the second tourney fails to be stored, so the first one is taken back.
+	writer := postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	assert.NotContains(t, writer.Body.String(), errStorageFull.Error())
+
+	storedTourneys, _, err := tourneys.ListByUser(user1ID, "", service.MaxTourneysPageLimit)
+	require.NoError(t, err)
+	assert.Empty(t, storedTourneys)
+
+	// a fair draw failing to be stored stays open.
+	writer = postJSON(t, router, "/draws", nil)
+	require.Equal(t, http.StatusCreated, writer.Code)
+
+	var draw converter.DrawDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&draw))
+
+	tourneyRequest.Candidates, tourneyRequest.Top, tourneyRequest.DrawID = 0, 0, draw.ID
+	tourneys.savesLeft = 0
+
+	writer = postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+
+	tourneys.savesLeft = 1
+
+	writer = postJSON(t, router, "/tourneys", tourneyRequest)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var result []converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+	require.Len(t, result, 1)
+
+	_, err = tourneys.Get(result[0].ID)
+	assert.NoError(t, err)
+}
+
+var errStorageFull = errors.New("storage is full")
+
+// limitedTourneyRepository fails to save tourneys once its saves are used up.
+type limitedTourneyRepository struct {
+	*adapters.MemoryTourneyRepository
+	savesLeft int
+}
+
+func (r *limitedTourneyRepository) Save(tourney *entity.Tourney) error {
+	if r.savesLeft == 0 {
+		return errStorageFull
+	}
+
+	r.savesLeft--
+
+	return r.MemoryTourneyRepository.Save(tourney)
+}
+
+func groupMeansVariance(tourney converter.TourneyDTO) float64 {
+	mean, result := 0.0, 0.0
+
+	for _, group := range tourney.Report.Groups {
+		mean += group.RatingMean / float64(len(tourney.Report.Groups))
+	}
+
+	for _, group := range tourney.Report.Groups {
+		result += (group.RatingMean - mean) * (group.RatingMean - mean) / float64(len(tourney.Report.Groups))
+	}
+
+	return result
+}
+
+// withoutIdentity clears what differs between the stored copies of the same draw.
+func withoutIdentity(tourneys []converter.TourneyDTO) []converter.TourneyDTO {
+	for i := range tourneys {
+		tourneys[i].ID, tourneys[i].CreatedAt = "", time.Time{}
+	}
+
+	return tourneys
+}
+
+// newTeamsServiceMock finds every team of the test data for any search.
+func newTeamsServiceMock(t *testing.T) *mock.MockTeams {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+
+	allTeams := readTeams(t, "../../test/data/teams.json")
+
+	teamsService := mock.NewMockTeams(ctrl)
+	teamsService.EXPECT().SearchTeams(float64(3), []string{}, "rating", 0).AnyTimes().Return(allTeams, nil)
+	teamsService.EXPECT().TeamsByID(gomock.Any()).AnyTimes().Return(allTeams, nil)
+
+	return teamsService
+}
+
+func readTeams(t *testing.T, path string) []dto.Team {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var teams []dto.Team
+
+	require.NoError(t, json.Unmarshal(data, &teams))
+
+	return teams
+}
+
+func postTourney(t *testing.T, teamsService *mock.MockTeams, tourneyRequest ports.GenerateTourneyRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return postJSON(t, newRouter(teamsService, service.TimeSeedSource{}), "/tourneys", tourneyRequest)
+}
+
+func newRouter(teamsService *mock.MockTeams, seedSource service.SeedSource) *mux.Router {
+	return newRouterWithRepository(teamsService, seedSource, adapters.NewMemoryTourneyRepository())
+}
+
+func newRouterWithRepository(
+	teamsService *mock.MockTeams,
+	seedSource service.SeedSource,
+	tourneys repository.TourneyRepository,
+) *mux.Router {
+	return newRouterWithRepositories(teamsService, seedSource, tourneys, adapters.NewMemoryDrawRepository())
+}
+
+func newRouterWithRepositories(
+	teamsService *mock.MockTeams,
+	seedSource service.SeedSource,
+	tourneys repository.TourneyRepository,
+	draws repository.DrawRepository,
+) *mux.Router {
+	tourneyGenerator := service.NewTourneyGenerator(teamsService, seedSource, tourneys)
+	server := ports.NewHTTPServer(
+		tourneyGenerator, service.NewTourneyCatalog(teamsService, tourneys), converter.NewConverter(teamsService), service.NewFairDraw(draws),
+	)
+
+	return ports.ConfigureRouter(server)
+}
+
+func postJSON(t *testing.T, router *mux.Router, path string, payload interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payloadJSON, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, path, bytes.NewBuffer(payloadJSON))
+	require.NoError(t, err)
+
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	return writer
 }
@@ -0,0 +1,99 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/ports/converter"
+)
+
+type TourneysPageDTO struct {
+	Tourneys   []converter.TourneyDTO `json:"tourneys"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+func (s HTTPServer) GetTourney(writer http.ResponseWriter, request *http.Request) {
+	overview, err := s.tourneyCatalog.Tourney(mux.Vars(request)["id"])
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("getting tourney error: %v\n", err)
+
+		return
+	}
+
+	tourneyDTOs, err := s.dtoConverter.TourneyOverviewsToDTOs([]*service.TourneyOverview{overview})
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("converting tourney entity to DTO error: %v\n", err)
+
+		return
+	}
+
+	if err = json.NewEncoder(writer).Encode(tourneyDTOs[0]); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
+
+// ListTourneys pages the tourneys of the user_id query parameter, the cursor and limit parameters are optional.
+func (s HTTPServer) ListTourneys(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	var invalidParams []InvalidParam
+
+	userID := query.Get("user_id")
+	if userID == "" {
+		invalidParams = append(invalidParams, InvalidParam{Name: "user_id", Reason: "is required"})
+	}
+
+	limit := 0
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			invalidParams = append(invalidParams, InvalidParam{Name: "limit", Reason: "must be a number"})
+		}
+	}
+
+	if len(invalidParams) > 0 {
+		writeProblem(writer, problemFromError(&validationError{invalidParams: invalidParams}, true))
+
+		return
+	}
+
+	overviews, nextCursor, err := s.tourneyCatalog.UserTourneys(userID, query.Get("cursor"), limit)
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("listing tourneys error: %v\n", err)
+
+		return
+	}
+
+	tourneyDTOs, err := s.dtoConverter.TourneyOverviewsToDTOs(overviews)
+	if err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("converting tourney entities to DTOs error: %v\n", err)
+
+		return
+	}
+
+	if err = json.NewEncoder(writer).Encode(TourneysPageDTO{Tourneys: tourneyDTOs, NextCursor: nextCursor}); err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		log.Printf("encoding response error: %v\n", err)
+	}
+}
+
+func (s HTTPServer) DeleteTourney(writer http.ResponseWriter, request *http.Request) {
+	if err := s.tourneyCatalog.DeleteTourney(mux.Vars(request)["id"]); err != nil {
+		writeProblem(writer, problemFromError(err, false))
+		log.Printf("deleting tourney error: %v\n", err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,179 @@
+package ports_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twizar/tourneys/internal/adapters"
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/entity"
+	"github.com/twizar/tourneys/internal/ports"
+	"github.com/twizar/tourneys/internal/ports/converter"
+)
+
+func TestHTTPServer_Tourneys(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouterWithRepository(teamsService, service.TimeSeedSource{}, adapters.NewMemoryTourneyRepository())
+
+	var generatedIDs []string
+
+	// the second tourney has no slots of user 1.
+	for i, tourneyRequest := range []ports.GenerateTourneyRequest{
+		{Candidates: 3, Top: 3, Users: []ports.UserParams{{UserID: user1ID}, {UserID: user2ID}}},
+		{Users: []ports.UserParams{{UserID: user3ID}, {UserID: user4ID}}},
+		{Users: []ports.UserParams{{UserID: user4ID}, {UserID: user1ID}}},
+	} {
+		tourneyRequest.GroupsCount, tourneyRequest.TeamsPerGroup, tourneyRequest.Leagues = 2, 4, []string{}
+
+		writer := postJSON(t, router, "/tourneys", tourneyRequest)
+		require.Equal(t, http.StatusOK, writer.Code)
+
+		var result []converter.TourneyDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&result))
+
+		for _, tourney := range result {
+			if i != 1 {
+				generatedIDs = append(generatedIDs, tourney.ID)
+			}
+		}
+	}
+
+	require.Len(t, generatedIDs, 4)
+
+	var (
+		listedIDs []string
+		pages     int
+		cursor    string
+	)
+
+	for {
+		writer := serve(t, router, http.MethodGet, "/tourneys?limit=3&user_id="+user1ID+"&cursor="+cursor)
+		require.Equal(t, http.StatusOK, writer.Code, writer.Body.String())
+
+		var page ports.TourneysPageDTO
+		require.NoError(t, json.NewDecoder(writer.Body).Decode(&page))
+
+		pages++
+
+		for _, tourney := range page.Tourneys {
+			listedIDs = append(listedIDs, tourney.ID)
+			require.NotEmpty(t, tourney.Groups[0].TeamSlots[0].Team.Name)
+		}
+
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, 2, pages)
+	assert.ElementsMatch(t, generatedIDs, listedIDs)
+	assert.Equal(t, generatedIDs[3], listedIDs[3])
+
+	writer := serve(t, router, http.MethodGet, "/tourneys/"+generatedIDs[0])
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var tourney converter.TourneyDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&tourney))
+	assert.Equal(t, generatedIDs[0], tourney.ID)
+	assert.Len(t, tourney.Groups, 2)
+
+	writer = serve(t, router, http.MethodDelete, "/tourneys/"+generatedIDs[0])
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		writer = serve(t, router, method, "/tourneys/"+generatedIDs[0])
+		assert.Equal(t, http.StatusNotFound, writer.Code, method)
+		assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+	}
+
+	writer = serve(t, router, http.MethodGet, "/tourneys?user_id="+user1ID)
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	var page ports.TourneysPageDTO
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&page))
+	assert.Len(t, page.Tourneys, 3)
+	assert.Empty(t, page.NextCursor)
+
+	writer = serve(t, router, http.MethodGet, "/tourneys?user_id=nobody")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&page))
+	assert.Empty(t, page.Tourneys)
+
+	for _, path := range []string{
+		"/tourneys",
+		"/tourneys?user_id=" + user1ID + "&limit=many",
+		"/tourneys?user_id=" + user1ID + "&limit=1000",
+		"/tourneys?user_id=" + user1ID + "&cursor=%21%21",
+	} {
+		writer = serve(t, router, http.MethodGet, path)
+		assert.Equal(t, http.StatusBadRequest, writer.Code, path)
+	}
+}
+
+func TestHTTPServer_Tourneys_StorageFailure(t *testing.T) {
+	t.Parallel()
+
+	teamsService := newTeamsServiceMock(t)
+
+	router := newRouterWithRepository(teamsService, service.TimeSeedSource{}, unavailableTourneyRepository{})
+
+	for _, request := range []struct{ method, path string }{
+		{method: http.MethodGet, path: "/tourneys?user_id=" + user1ID},
+		{method: http.MethodGet, path: "/tourneys/" + user1ID},
+		{method: http.MethodDelete, path: "/tourneys/" + user1ID},
+		{method: http.MethodPost, path: "/tourneys/" + user1ID + "/results"},
+	} {
+		var writer *httptest.ResponseRecorder
+		if request.method == http.MethodPost {
+			writer = postJSON(t, router, request.path, ports.SubmitResultsRequest{})
+		} else {
+			writer = serve(t, router, request.method, request.path)
+		}
+
+		assert.Equal(t, http.StatusServiceUnavailable, writer.Code, request.path)
+		assert.NotContains(t, writer.Body.String(), errStorageDown.Error(), request.path)
+	}
+}
+
+var errStorageDown = errors.New("storage is down")
+
+// unavailableTourneyRepository fails every call as a storage which can't be reached.
+type unavailableTourneyRepository struct{}
+
+func (unavailableTourneyRepository) Save(*entity.Tourney) error {
+	return errStorageDown
+}
+
+func (unavailableTourneyRepository) Get(string) (*entity.Tourney, error) {
+	return nil, errStorageDown
+}
+
+func (unavailableTourneyRepository) ListByUser(string, string, int) ([]*entity.Tourney, string, error) {
+	return nil, "", errStorageDown
+}
+
+func (unavailableTourneyRepository) Delete(string) error {
+	return errStorageDown
+}
+
+func serve(t *testing.T, router *mux.Router, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(context.Background(), method, path, nil)
+	require.NoError(t, err)
+
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	return writer
+}
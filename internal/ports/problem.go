@@ -0,0 +1,186 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/twizar/tourneys/internal/application/service"
+	"github.com/twizar/tourneys/internal/domain/repository"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	problemTypeInvalidRequest = "/problems/invalid-request"
+	problemTypeUnprocessable  = "/problems/unprocessable-draw"
+	problemTypeTeamsService   = "/problems/teams-service"
+	problemTypeStorage        = "/problems/storage"
+	problemTypeFairDraw       = "/problems/fair-draw"
+	problemTypeDrawSearch     = "/problems/draw-search-exhausted"
+	problemTypeNotFound       = "/problems/not-found"
+	problemTypeConflict       = "/problems/conflict"
+	problemTypeInternal       = "/problems/internal"
+)
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+	// AvailableTeams and NeededTeams tell how short the teams pool of the rating range is.
+	AvailableTeams *int `json:"available_teams,omitempty"`
+	NeededTeams    *int `json:"needed_teams,omitempty"`
+}
+
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// validationError lists the request fields failed validation.
+type validationError struct {
+	invalidParams []InvalidParam
+}
+
+func (e *validationError) Error() string {
+	return "invalid request fields"
+}
+
+// problemFromError tells the request problems (400) and the missing tourneys (404) from the draws
+// which can't be made (422), the teams service failures (502), the storage failures and the draws the solver
+// gave up on (503), anything else is internal (500).
+func problemFromError(err error, requestErr bool) Problem {
+	var (
+		invalidRequest     *validationError
+		settingsError      *service.SettingsError
+		teamsServiceError  *service.TeamsServiceError
+		storageError       *service.StorageError
+		infeasibleError    *service.InfeasibleError
+		searchError        *service.SearchExhaustedError
+		poolExhaustedError *service.PoolExhaustedError
+		unknownTeamsError  *service.UnknownTeamsError
+	)
+
+	switch {
+	case errors.As(err, &invalidRequest):
+		return Problem{
+			Type:          problemTypeInvalidRequest,
+			Title:         "Invalid tourney request",
+			Status:        http.StatusBadRequest,
+			InvalidParams: invalidRequest.invalidParams,
+		}
+	case errors.As(err, &settingsError):
+		return Problem{
+			Type:          problemTypeInvalidRequest,
+			Title:         "Invalid tourney request",
+			Status:        http.StatusBadRequest,
+			InvalidParams: []InvalidParam{{Name: settingsError.Setting, Reason: settingsError.Reason}},
+		}
+	case errors.Is(err, repository.ErrTourneyNotFound):
+		return Problem{
+			Type:   problemTypeNotFound,
+			Title:  "Tourney not found",
+			Status: http.StatusNotFound,
+			Detail: err.Error(),
+		}
+	case errors.Is(err, repository.ErrVersionConflict):
+		return Problem{
+			Type:   problemTypeConflict,
+			Title:  "Tourney changed meanwhile",
+			Status: http.StatusConflict,
+			Detail: "the tourney was changed or deleted by another request, read it again and retry",
+		}
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return Problem{
+			Type:          problemTypeInvalidRequest,
+			Title:         "Invalid tourneys request",
+			Status:        http.StatusBadRequest,
+			InvalidParams: []InvalidParam{{Name: "cursor", Reason: "is malformed"}},
+		}
+	case errors.Is(err, service.ErrPageLimit):
+		return Problem{
+			Type:          problemTypeInvalidRequest,
+			Title:         "Invalid tourneys request",
+			Status:        http.StatusBadRequest,
+			InvalidParams: []InvalidParam{{Name: "limit", Reason: fmt.Sprintf("must be within 1 and %d", service.MaxTourneysPageLimit)}},
+		}
+	case errors.Is(err, repository.ErrTourneyTooLarge):
+		return Problem{
+			Type:   problemTypeUnprocessable,
+			Title:  "Tourney too large to store",
+			Status: http.StatusUnprocessableEntity,
+			Detail: "the tourney exceeds the size the storage takes, draw fewer or smaller groups",
+		}
+	case errors.As(err, &storageError):
+		return Problem{
+			Type:   problemTypeStorage,
+			Title:  "Tourneys storage unavailable",
+			Status: http.StatusServiceUnavailable,
+		}
+	case errors.As(err, &teamsServiceError):
+		return Problem{
+			Type:   problemTypeTeamsService,
+			Title:  "Teams service failure",
+			Status: http.StatusBadGateway,
+		}
+	case errors.As(err, &infeasibleError):
+		return Problem{
+			Type:   problemTypeUnprocessable,
+			Title:  "Draw constraints can't be satisfied",
+			Status: http.StatusUnprocessableEntity,
+			Detail: infeasibleError.Error(),
+		}
+	case errors.As(err, &searchError):
+		return Problem{
+			Type:   problemTypeDrawSearch,
+			Title:  "No draw found in time",
+			Status: http.StatusServiceUnavailable,
+			Detail: searchError.Error(),
+		}
+	case errors.As(err, &poolExhaustedError):
+		return Problem{
+			Type:   problemTypeUnprocessable,
+			Title:  "Not enough teams for the draw",
+			Status: http.StatusUnprocessableEntity,
+			Detail: fmt.Sprintf(
+				"rating range [%v, %v] yields %d teams, %d needed",
+				poolExhaustedError.MinRating, poolExhaustedError.MaxRating, poolExhaustedError.Available, poolExhaustedError.Needed,
+			),
+			AvailableTeams: &poolExhaustedError.Available,
+			NeededTeams:    &poolExhaustedError.Needed,
+		}
+	case errors.As(err, &unknownTeamsError):
+		return Problem{
+			Type:   problemTypeUnprocessable,
+			Title:  "Unknown required teams",
+			Status: http.StatusUnprocessableEntity,
+			Detail: unknownTeamsError.Error(),
+		}
+	case requestErr:
+		return Problem{
+			Type:   problemTypeInvalidRequest,
+			Title:  "Invalid tourney request",
+			Status: http.StatusBadRequest,
+		}
+	default:
+		return Problem{
+			Type:   problemTypeInternal,
+			Title:  "Internal error",
+			Status: http.StatusInternalServerError,
+		}
+	}
+}
+
+func writeProblem(writer http.ResponseWriter, problem Problem) {
+	writer.Header().Set("Content-Type", problemContentType)
+	writer.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(writer).Encode(problem); err != nil {
+		log.Printf("encoding problem error: %v\n", err)
+	}
+}
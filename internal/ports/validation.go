@@ -0,0 +1,217 @@
+package ports
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/twizar/tourneys/internal/application/service"
+)
+
+// userIDPattern checks the format of user IDs only: there is no users service to ask whether a user exists,
+// so a well-formed ID of an unknown user passes.
+var userIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// invalidParams collects the request fields failed validation.
+type invalidParams []InvalidParam
+
+func (p *invalidParams) add(name, reason string, args ...interface{}) {
+	*p = append(*p, InvalidParam{Name: name, Reason: fmt.Sprintf(reason, args...)})
+}
+
+func (p invalidParams) err() error {
+	if len(p) > 0 {
+		return &validationError{invalidParams: p}
+	}
+
+	return nil
+}
+
+// validate checks the request fields which don't need the teams to be known.
+func (r GenerateTourneyRequest) validate() error {
+	var invalid invalidParams
+
+	slotsCount := r.validateGroupSizes(&invalid)
+	r.validateOptions(&invalid)
+	r.validateGroupNames(&invalid)
+	r.validateUsers(&invalid, slotsCount)
+
+	return invalid.err()
+}
+
+// validateGroupSizes checks the caps before the slots are counted, so the count can't overflow,
+// and returns the slots count, zero when the sizes are invalid.
+func (r GenerateTourneyRequest) validateGroupSizes(invalid *invalidParams) int {
+	groupsCountValid := r.GroupsCount >= 1 && r.GroupsCount <= service.MaxGroupsCount
+	if !groupsCountValid {
+		invalid.add("groups_count", "must be within 1 and %d, got %d", service.MaxGroupsCount, r.GroupsCount)
+	}
+
+	if r.TeamsCount != 0 {
+		switch {
+		case r.TeamsPerGroup != 0:
+			invalid.add("teams_count", "can't be combined with teams_per_group")
+		case r.TeamsCount < 1 || r.TeamsCount > service.MaxTeamsCount:
+			invalid.add("teams_count", "must be within 1 and %d, got %d", service.MaxTeamsCount, r.TeamsCount)
+		case !groupsCountValid:
+		case r.TeamsCount < r.GroupsCount:
+			invalid.add("teams_count", "%d teams can't fill %d groups", r.TeamsCount, r.GroupsCount)
+		default:
+			return r.TeamsCount
+		}
+
+		return 0
+	}
+
+	switch {
+	case r.TeamsPerGroup < 1 || r.TeamsPerGroup > service.MaxTeamsPerGroup:
+		invalid.add("teams_per_group", "must be within 1 and %d, got %d", service.MaxTeamsPerGroup, r.TeamsPerGroup)
+	case !groupsCountValid:
+	case r.GroupsCount*r.TeamsPerGroup > service.MaxTeamsCount:
+		invalid.add("teams_per_group", "%d groups of %d teams exceed %d teams", r.GroupsCount, r.TeamsPerGroup, service.MaxTeamsCount)
+	default:
+		return r.GroupsCount * r.TeamsPerGroup
+	}
+
+	return 0
+}
+
+func (r GenerateTourneyRequest) validateOptions(invalid *invalidParams) {
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{name: "max_user_slots_per_group", value: r.MaxUserSlotsPerGroup},
+		{name: "candidates", value: r.Candidates},
+		{name: "top", value: r.Top},
+		{name: "rebalance_iterations", value: r.RebalanceIterations},
+		{name: "knockout_qualifiers", value: r.KnockoutQualifiers},
+	} {
+		if field.value < 0 {
+			invalid.add(field.name, "must not be negative, got %d", field.value)
+		}
+	}
+
+	if r.MaxUserSlotsPerGroup > 0 && r.UserSpread != nil && !*r.UserSpread {
+		invalid.add("max_user_slots_per_group", "can't be set when user_spread is disabled")
+	}
+}
+
+func (r GenerateTourneyRequest) validateGroupNames(invalid *invalidParams) {
+	if len(r.GroupNames) > 0 && r.GroupNaming != "" {
+		invalid.add("group_names", "can't be combined with group_naming")
+	}
+
+	if len(r.GroupNames) > 0 && len(r.GroupNames) != r.GroupsCount {
+		invalid.add("group_names", "%d names given for %d groups", len(r.GroupNames), r.GroupsCount)
+	}
+
+	groupIndexByName := make(map[string]int, len(r.GroupNames))
+
+	for i, name := range r.GroupNames {
+		switch previous, exists := groupIndexByName[name]; {
+		case name == "":
+			invalid.add(fmt.Sprintf("group_names[%d]", i), "is empty")
+		case exists:
+			invalid.add(fmt.Sprintf("group_names[%d]", i), "duplicates group_names[%d]", previous)
+		default:
+			groupIndexByName[name] = i
+		}
+	}
+}
+
+// validateUsers checks the users against each other and against the slots count, zero slots count skips the latter.
+func (r GenerateTourneyRequest) validateUsers(invalid *invalidParams, slotsCount int) {
+	if len(r.Users) == 0 {
+		invalid.add("users", "at least one user is required")
+	}
+
+	requestedTeamsCount, requiredTeamsCount := 0, 0
+	userIndexByID := make(map[string]int, len(r.Users))
+	userIndexByRequiredTeamID := make(map[string]int)
+
+	for i, user := range r.Users {
+		switch previous, exists := userIndexByID[user.UserID]; {
+		case user.UserID == "":
+			invalid.add(fmt.Sprintf("users[%d].user_id", i), "is required")
+		case !userIDPattern.MatchString(user.UserID):
+			invalid.add(fmt.Sprintf("users[%d].user_id", i), "`%s` isn't a UUID", user.UserID)
+		case exists:
+			invalid.add(fmt.Sprintf("users[%d].user_id", i), "duplicates users[%d]", previous)
+		default:
+			userIndexByID[user.UserID] = i
+		}
+
+		if user.TeamsCount < 0 {
+			invalid.add(fmt.Sprintf("users[%d].teams_count", i), "must not be negative, got %d", user.TeamsCount)
+		}
+
+		if user.TeamsCount > 0 && len(user.RequiredTeams) > user.TeamsCount {
+			invalid.add(
+				fmt.Sprintf("users[%d].required_teams", i),
+				"%d required teams exceed the teams count %d", len(user.RequiredTeams), user.TeamsCount,
+			)
+		}
+
+		r.validateRequiredTeams(invalid, i, userIndexByRequiredTeamID)
+
+		requestedTeamsCount += user.TeamsCount
+		requiredTeamsCount += len(user.RequiredTeams)
+	}
+
+	if slotsCount > 0 && requestedTeamsCount > slotsCount {
+		invalid.add("users", "users request %d teams, tourney has %d slots", requestedTeamsCount, slotsCount)
+	}
+
+	if slotsCount > 0 && requiredTeamsCount > slotsCount {
+		invalid.add("users", "users require %d teams, tourney has %d slots", requiredTeamsCount, slotsCount)
+	}
+}
+
+// validateRequiredTeams checks the required teams of the user, userIndexByRequiredTeamID keeps the first user
+// requiring every team so far.
+func (r GenerateTourneyRequest) validateRequiredTeams(invalid *invalidParams, userIndex int, userIndexByRequiredTeamID map[string]int) {
+	rejectContestedTeams := r.RequiredTeamsPolicy == "" || r.RequiredTeamsPolicy == service.RequiredTeamsPolicyReject
+	userRequiredTeamIDs := make(map[string]bool, len(r.Users[userIndex].RequiredTeams))
+
+	for j, teamID := range r.Users[userIndex].RequiredTeams {
+		name := fmt.Sprintf("users[%d].required_teams[%d]", userIndex, j)
+
+		switch previous, exists := userIndexByRequiredTeamID[teamID]; {
+		case teamID == "":
+			invalid.add(name, "is empty")
+		case userRequiredTeamIDs[teamID]:
+			invalid.add(name, "team `%s` is listed twice", teamID)
+		case exists && rejectContestedTeams:
+			invalid.add(name, "team `%s` is required by users[%d] too", teamID, previous)
+		case exists:
+		default:
+			userIndexByRequiredTeamID[teamID] = userIndex
+		}
+
+		userRequiredTeamIDs[teamID] = true
+	}
+}
+
+// validateFairDraw rejects the options a fair draw can't take: its seed comes from the draw and it makes
+// a single tourney, so nobody picks among candidates once the server seed is revealed.
+func (r GenerateTourneyRequest) validateFairDraw(prefix string) error {
+	var invalid invalidParams
+
+	if r.Seed != nil {
+		invalid.add(prefix+"seed", "can't be set for a fair draw")
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{name: "candidates", value: r.Candidates},
+		{name: "top", value: r.Top},
+	} {
+		if field.value > 1 {
+			invalid.add(prefix+field.name, "must be at most 1 for a fair draw, got %d", field.value)
+		}
+	}
+
+	return invalid.err()
+}